@@ -0,0 +1,103 @@
+package main
+
+import (
+	"math/rand"
+	"sync"
+
+	pb "main/client/proto"
+	config "main/config"
+	"main/server"
+	utills "main/utills"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// First ElectionTime stamp of every replay_time vehicle of the run, which it replays in every later election.
+var REPLAYED_STAMPS map[int32]*pb.Vehicle
+var replayMu sync.Mutex
+
+// Function name: selectAdversaries
+// Draws the malicious vehicles of a run among the vehicles that are not HVs, adds the explicitly listed ones,
+// and returns the strategy of each together with the HVs that remain. A listed vehicle is a CAV even if it
+// was drawn as an HV, since a vehicle that never talks cannot misbehave.
+func selectAdversaries(r *rand.Rand, vehicles []int, hvVehicles []int, adversaries config.Adversaries) (map[int32]string, []int) {
+	strategies := make(map[int32]string)
+	if !adversaries.Enabled() {
+		return strategies, hvVehicles
+	}
+
+	var cavs []int
+	for _, v := range vehicles {
+		if !utills.ContainsInt(hvVehicles, v) {
+			cavs = append(cavs, v)
+		}
+	}
+	n := int(float64(len(vehicles)) * adversaries.Ratio)
+	if n > len(cavs) {
+		n = len(cavs)
+	}
+	for _, v := range selectRandomVehicles(r, cavs, n) {
+		strategies[int32(v)] = adversaries.Strategy
+	}
+
+	var hvs []int
+	for _, v := range hvVehicles {
+		if _, listed := adversaries.Vehicles[int32(v)]; !listed {
+			hvs = append(hvs, v)
+		}
+	}
+	for number, strategy := range adversaries.Vehicles {
+		strategies[number] = strategy
+	}
+	return strategies, hvs
+}
+
+// Function name: reportedDirections
+// Returns the movements as the leader knows them from the votes it received: the real ones, except that
+// every false_direction vehicle claims a movement the leader may cross with.
+func reportedDirections(leader int32) map[int32]string {
+	directions := make(map[int32]string, len(DIRECTIONS))
+	for k, d := range DIRECTIONS {
+		directions[k] = d
+		if k != leader && STRATEGIES[k] == "false_direction" {
+			directions[k] = server.FalseDirection(DIRECTIONS[leader], d)
+		}
+	}
+	return directions
+}
+
+// Function name: claimedVotes
+// Returns the vehicle as its candidate presents it in LeaderElection. An inflate_votes candidate claims
+// a vote from every other vehicle of the round.
+func claimedVotes(vehicle *pb.Vehicle) *pb.Vehicle {
+	if STRATEGIES[vehicle.Number] != "inflate_votes" {
+		return vehicle
+	}
+	claim := proto.Clone(vehicle).(*pb.Vehicle)
+	// releaseVehicle removes passed vehicles from VEHICLES under commitMu
+	commitMu.Lock()
+	claim.ReceiveVotes = int32(len(VEHICLES)) - 1
+	commitMu.Unlock()
+	return claim
+}
+
+// Function name: stampElection
+// Stamps a candidate's record when it receives a vote: with its current clocks, or for a replay_time
+// vehicle with the first stamp it used in the run.
+func stampElection(number int32, vehicle *pb.Vehicle) {
+	RUN.Stamp(number, vehicle)
+	if STRATEGIES[number] != "replay_time" {
+		return
+	}
+	replayMu.Lock()
+	defer replayMu.Unlock()
+	first, ok := REPLAYED_STAMPS[number]
+	if !ok {
+		REPLAYED_STAMPS[number] = &pb.Vehicle{ElectionTime: vehicle.ElectionTime, Lamport: vehicle.Lamport, Hlc: vehicle.Hlc}
+		return
+	}
+	replayed := proto.Clone(first).(*pb.Vehicle)
+	vehicle.ElectionTime = replayed.ElectionTime
+	vehicle.Lamport = replayed.Lamport
+	vehicle.Hlc = replayed.Hlc
+}
@@ -0,0 +1,402 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	pb "main/client/proto"
+	quorum "main/quorum"
+	"main/server"
+	utills "main/utills"
+)
+
+// bftDecision is a schedule decided in a view and the number of replicas that decided it.
+type bftDecision struct {
+	Schedule *pb.Schedule
+	Replicas int
+}
+
+// bftView tracks the messages of one BFT view on the client side, which plays the vehicles' processes.
+type bftView struct {
+	mu           sync.Mutex
+	wg           sync.WaitGroup
+	view         int32
+	replicas     []int32
+	schedules    map[string]*pb.Schedule       // proposals of the view, by digest
+	prepared     map[string]bool               // replica and digest pairs that already sent Commit
+	decided      map[int32]string              // digest each replica decided
+	certificates map[string][]*pb.SignedCommit // commit certificate of each decided digest
+	order        []string                      // decided digests in the order they were first decided
+	jitter       map[[2]int32]time.Duration    // delay of the messages from one replica to another
+}
+
+// Function name: bftElection
+// Runs the PBFT-style election of one round. The roster is every vehicle of the round, HVs included, so up to
+// f = (n-1)/3 of them may be silent or malicious. In every view the primary of the roster proposes the passing
+// schedule (PrePrepare); every replica that accepts it broadcasts Prepare, every replica that collects 2f+1
+// Prepare broadcasts Commit, and a replica that collects 2f+1 Commit decides. A view that does not decide is
+// given up after the view timeout, and the next primary first collects the locks of 2f+1 replicas (ViewChange).
+// Returns the schedules that were decided, one in a correct run.
+func bftElection(roster []int32, replicas []int32, viewTimeout time.Duration, expired func() bool) []bftDecision {
+	if !(quorum.Byzantine{}).Reached(replicas, quorum.Round{Vehicles: roster}) {
+		return nil
+	}
+	broadcast(replicas, func(k int32) {
+		client, conn, ctx, cancel, err := rpcConnectTo(0, k)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		defer cancel()
+		client.BftRoster(ctx, &pb.Request{Roster: roster})
+	})
+
+	for view := int32(1); ; view++ {
+		METRICS.Term()
+		viewStart := CLOCK.Now()
+		if decided := runView(view, roster, replicas); len(decided) > 0 {
+			return decided
+		}
+		CLOCK.SleepUntil(viewStart.Add(viewTimeout))
+		if expired() {
+			return nil
+		}
+	}
+}
+
+// Function name: runView
+// Runs one view and returns the schedules its replicas decided.
+func runView(view int32, roster []int32, replicas []int32) []bftDecision {
+	primary := server.Primary(roster, view)
+	// a silent HV or a crashed vehicle never proposes: the view times out
+	if !utills.Contains(replicas, primary) || RUN.Down(primary) {
+		return nil
+	}
+
+	// like the vote requests of the crash election, every message of a view is delayed by the jitter of its
+	// sender and receiver, counted from the moment the sender starts its phase
+	jitter := drawJitter(replicas)
+	groups := passingGroups(primary, replicas)
+	if view > 1 {
+		locked, ok := viewChange(primary, view, roster, replicas, jitter)
+		if !ok {
+			return nil
+		}
+		if locked != nil {
+			groups = nil
+			for _, group := range locked.Groups {
+				groups = append(groups, group.Vehicles)
+			}
+		}
+	}
+
+	leader := &pb.Vehicle{Number: primary, Address: primary}
+	RUN.Stamp(primary, leader)
+	proposal := newSchedule(view, primary, groups)
+	proposals := map[int32]*pb.Schedule{}
+	// an equivocating primary sends a different schedule to the second half of the replicas
+	other := equivocation(view, primary, groups)
+	for i, k := range replicas {
+		proposals[k] = proposal
+		if STRATEGIES[primary] == "equivocate" && other != nil && i >= len(replicas)/2 {
+			proposals[k] = other
+		}
+	}
+
+	v := &bftView{
+		view:         view,
+		replicas:     replicas,
+		schedules:    make(map[string]*pb.Schedule),
+		prepared:     make(map[string]bool),
+		decided:      make(map[int32]string),
+		certificates: make(map[string][]*pb.SignedCommit),
+		jitter:       jitter,
+	}
+	for _, schedule := range proposals {
+		v.schedules[hex.EncodeToString(server.ProposalDigest(schedule, leader))] = schedule
+	}
+
+	start := CLOCK.Now()
+	for _, k := range replicas {
+		v.wg.Add(1)
+		go func(k int32) {
+			defer v.wg.Done()
+			CLOCK.SleepUntil(start.Add(jitter[[2]int32{primary, k}]))
+			client, conn, ctx, cancel, err := rpcConnectTo(primary, k)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			defer cancel()
+
+			r, _ := client.BftPrePrepare(ctx, &pb.Request{Vehicle: leader, View: view, Schedule: proposals[k]})
+			if r == nil || r.Status == "ignored" || r.Status == "rejected" {
+				return
+			}
+			v.accepted(k, r)
+		}(k)
+	}
+	v.wg.Wait()
+	v.catchUp(leader)
+
+	var decided []bftDecision
+	for _, digest := range v.order {
+		decision := bftDecision{Schedule: v.schedules[digest]}
+		for _, d := range v.decided {
+			if d == digest {
+				decision.Replicas++
+			}
+		}
+		decided = append(decided, decision)
+	}
+	return decided
+}
+
+// Function name: releaseDecided
+// Lets the groups of every decided schedule cross one after another and returns the time the crossings took.
+// More than one schedule means the replicas disagree: each schedule is followed at the same time by the
+// replicas that decided it, and the agreement check and the safety monitor then report it.
+func releaseDecided(decided []bftDecision, crossTime time.Duration) time.Duration {
+	schedules := make([][][]int32, len(decided))
+	commitMu.Lock()
+	for d, decision := range decided {
+		for _, group := range decision.Schedule.Groups {
+			schedules[d] = append(schedules[d], group.Vehicles)
+		}
+		METRICS.Leader(decision.Schedule.Leader)
+		LEADERS = append(LEADERS, decision.Schedule.Leader)
+		METRICS.Commit(schedules[d], decision.Replicas)
+	}
+	commitMu.Unlock()
+
+	crossed := make([]time.Duration, len(schedules))
+	var wg sync.WaitGroup
+	for d, groups := range schedules {
+		wg.Add(1)
+		go func(d int, groups [][]int32) {
+			defer wg.Done()
+			crossed[d] = crossGroups(groups, crossTime)
+		}(d, groups)
+	}
+	wg.Wait()
+
+	var longest time.Duration
+	for _, c := range crossed {
+		longest = max(longest, c)
+	}
+	return longest
+}
+
+// Function name: accepted
+// Replica k accepted a proposal: it broadcasts its signed Prepare for it. An equivocating replica also returns
+// its signed Commit right away, so it commits without waiting for 2f+1 Prepare.
+func (v *bftView) accepted(k int32, r *pb.Response) {
+	v.send(k, r.Digest, &pb.Request{Prepare: r.Prepare})
+	v.progress(k, r.Digest, r)
+}
+
+// Function name: progress
+// Follows the state a response reported for replica k: once it returns its signed Commit it broadcasts it,
+// once committed it has decided the proposal with the returned commit certificate.
+func (v *bftView) progress(k int32, digest []byte, r *pb.Response) {
+	key := hex.EncodeToString(digest)
+
+	v.mu.Lock()
+	first := r.Commit != nil && !v.prepared[voteKey(k, key)]
+	if first {
+		v.prepared[voteKey(k, key)] = true
+	}
+	if r.Status == "committed" {
+		v.decide(k, key, r.Commits)
+	}
+	v.mu.Unlock()
+
+	if first {
+		v.send(k, digest, &pb.Request{Commit: r.Commit})
+	}
+}
+
+// Function name: catchUp
+// Every replica that decided reports its decision with its commit certificate to the replicas that did not,
+// so that they decide it too.
+func (v *bftView) catchUp(leader *pb.Vehicle) {
+	v.mu.Lock()
+	decided := make(map[int32]string)
+	for k, digest := range v.decided {
+		decided[k] = digest
+	}
+	certificates := make(map[string][]*pb.SignedCommit)
+	for key, certificate := range v.certificates {
+		certificates[key] = certificate
+	}
+	v.mu.Unlock()
+	if len(decided) == 0 {
+		return
+	}
+
+	start := CLOCK.Now()
+	for k, key := range decided {
+		if RUN.Down(k) {
+			continue
+		}
+		for _, j := range v.replicas {
+			if _, ok := decided[j]; ok {
+				continue
+			}
+			v.wg.Add(1)
+			go func(k int32, j int32) {
+				defer v.wg.Done()
+				CLOCK.SleepUntil(start.Add(v.jitter[[2]int32{k, j}]))
+				client, conn, ctx, cancel, err := rpcConnectTo(k, j)
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				defer cancel()
+
+				req := &pb.Request{Sender: k, Vehicle: leader, View: v.view, Schedule: v.schedules[key], Commits: certificates[key]}
+				r, _ := client.BftDecided(ctx, req)
+				if r != nil && r.Status == "committed" {
+					v.mu.Lock()
+					v.decide(j, key, r.Commits)
+					v.mu.Unlock()
+				}
+			}(k, j)
+		}
+	}
+	v.wg.Wait()
+}
+
+// Function name: decide
+// Records that replica k decided the proposal with the commit certificate. Must be called with v.mu held.
+func (v *bftView) decide(k int32, key string, certificate []*pb.SignedCommit) {
+	if _, ok := v.certificates[key]; !ok && len(certificate) > 0 {
+		v.certificates[key] = certificate
+	}
+	if _, ok := v.decided[k]; ok {
+		return
+	}
+	v.decided[k] = key
+	for _, digest := range v.order {
+		if digest == key {
+			return
+		}
+	}
+	v.order = append(v.order, key)
+}
+
+// Function name: send
+// Broadcasts the signed Prepare or Commit that the message holds for replica k to every replica and follows
+// the state each one reports.
+func (v *bftView) send(k int32, digest []byte, message *pb.Request) {
+	if RUN.Down(k) {
+		return
+	}
+	start := CLOCK.Now()
+	for _, j := range v.replicas {
+		v.wg.Add(1)
+		go func(j int32) {
+			defer v.wg.Done()
+			CLOCK.SleepUntil(start.Add(v.jitter[[2]int32{k, j}]))
+			client, conn, ctx, cancel, err := rpcConnectTo(k, j)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			defer cancel()
+
+			req := &pb.Request{Vehicle: &pb.Vehicle{Number: k, Address: k}, View: v.view, Digest: digest, Prepare: message.Prepare, Commit: message.Commit}
+			var r *pb.Response
+			if message.Prepare != nil {
+				r, _ = client.BftPrepare(ctx, req)
+			} else {
+				r, _ = client.BftCommit(ctx, req)
+			}
+			if r != nil {
+				v.progress(j, digest, r)
+			}
+		}(j)
+	}
+}
+
+// Function name: viewChange
+// The primary of the view collects the locks of the replicas. It needs answers from 2f+1 replicas of the
+// roster and returns the schedule of the highest view any of them is locked on, nil if none is locked.
+func viewChange(primary int32, view int32, roster []int32, replicas []int32, jitter map[[2]int32]time.Duration) (*pb.Schedule, bool) {
+	var mu sync.Mutex
+	var answered []int32
+	var locked *pb.Schedule
+	var lockedView int32
+	start := CLOCK.Now()
+	broadcast(replicas, func(k int32) {
+		CLOCK.SleepUntil(start.Add(jitter[[2]int32{primary, k}]))
+		client, conn, ctx, cancel, err := rpcConnectTo(primary, k)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		defer cancel()
+
+		r, _ := client.BftViewChange(ctx, &pb.Request{Vehicle: &pb.Vehicle{Number: primary, Address: primary}, View: view})
+		if r == nil || r.Status != "acknowledged" {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		answered = append(answered, k)
+		if r.Schedule != nil && r.View > lockedView {
+			locked = r.Schedule
+			lockedView = r.View
+		}
+	})
+	return locked, quorum.Byzantine{}.Reached(answered, quorum.Round{Vehicles: roster})
+}
+
+// Function name: equivocation
+// Returns the second schedule an equivocating primary proposes: its groups in reverse order, or the primary
+// crossing alone. Returns nil if no different schedule exists.
+func equivocation(view int32, primary int32, groups [][]int32) *pb.Schedule {
+	if len(groups) > 1 {
+		reversed := make([][]int32, 0, len(groups))
+		for i := len(groups) - 1; i >= 0; i-- {
+			reversed = append(reversed, groups[i])
+		}
+		return newSchedule(view, primary, reversed)
+	}
+	if len(groups) == 1 && len(groups[0]) > 1 {
+		return newSchedule(view, primary, [][]int32{{primary}})
+	}
+	return nil
+}
+
+// Function name: newSchedule
+// Builds the schedule message of the groups.
+func newSchedule(term int32, leader int32, groups [][]int32) *pb.Schedule {
+	schedule := &pb.Schedule{Term: term, Leader: leader}
+	for _, group := range groups {
+		schedule.Groups = append(schedule.Groups, &pb.PassGroup{Vehicles: group})
+	}
+	return schedule
+}
+
+// Function name: broadcast
+// Runs the send function for every vehicle concurrently and waits for all of them.
+func broadcast(vehicles []int32, send func(k int32)) {
+	var wg sync.WaitGroup
+	for _, k := range vehicles {
+		wg.Add(1)
+		go func(k int32) {
+			defer wg.Done()
+			send(k)
+		}(k)
+	}
+	wg.Wait()
+}
+
+// Function name: voteKey
+// Returns the key of a replica and proposal pair.
+func voteKey(k int32, digest string) string {
+	return fmt.Sprintf("%d/%s", k, digest)
+}
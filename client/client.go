@@ -1,21 +1,31 @@
-﻿package main
+package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"log"
 	"main/server"
 	"math/rand"
+	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	pb "main/client/proto"
+	clock "main/clock"
 	config "main/config"
 	direction "main/config/directionBoolean"
+	identity "main/identity"
+	metrics "main/metrics"
+	quorum "main/quorum"
+	transport "main/transport"
 	utills "main/utills"
+	verify "main/verify"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
-	pbtimestamp "google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/protobuf/proto"
 )
 
 // global variable //
@@ -24,62 +34,332 @@ var GO_SERVER_PORT = config.BasePort
 var TOTAL_VEHICLES int32
 var PASS_COUNT int
 
+// Time source of the current run (wall clock or virtual clock).
+var CLOCK clock.Clock = clock.Real{}
+
+// Network between the vehicle nodes of the current run (TCP ports or in-memory).
+var TRANSPORT transport.Transport = transport.TCP{BasePort: config.BasePort}
+
+// Vehicle servers of the current run: their settings, local clocks and counters.
+var RUN *server.Run
+
+// Fault-injecting network of the current run, nil on a perfect network.
+var FAULTS *transport.Faulty
+
+// Partitioned network of the current run, nil if the scenario has no partition.
+var PARTITION *transport.Partition
+
+// Round and vehicle measurements of the current run.
+var METRICS *metrics.Recorder
+
+// Safety monitor of the current run: checks the movements of vehicles inside the intersection together.
+var SAFETY *verify.Monitor
+
+// Serializes the leader's schedule commit with the release of its group.
+var commitMu sync.Mutex
+
+// Leaders committed in the current election; more than one is an agreement violation.
+var LEADERS []int32
+
+// What an election decides in the current run: "leader" (one passing group) or "schedule" (every waiting CAV).
+var PROTOCOL string
+
+// Strategy of every malicious vehicle of the current run, by vehicle number.
+var STRATEGIES map[int32]string
+
+// Movement and license plate of every vehicle of the current run.
+var DIRECTIONS map[int32]string
+var PLATES map[int32]string
+
+// Random streams of the current run, one per subsystem, all derived from the run seed.
+var RNG randomStreams
+
+type randomStreams struct {
+	hv        *rand.Rand // which vehicles are human-driven
+	round     *rand.Rand // round size and participating vehicles
+	direction *rand.Rand // movement of each vehicle
+	jitter    *rand.Rand // delay before each vote request
+	stopped   *rand.Rand // which stopped HVs pass after an election
+	agreement *rand.Rand // values drawn in RandomAgreement
+	plate     *rand.Rand // license plates
+	adversary *rand.Rand // which CAVs are malicious
+	skew      *rand.Rand // local clock skew of each vehicle
+}
+
+// Function name: newRandomStreams
+// Splits the run seed into the per-subsystem random streams.
+func newRandomStreams(seed int64) randomStreams {
+	return randomStreams{
+		hv:        utills.NewStream(seed, "hv"),
+		round:     utills.NewStream(seed, "round"),
+		direction: utills.NewStream(seed, "direction"),
+		jitter:    utills.NewStream(seed, "jitter"),
+		stopped:   utills.NewStream(seed, "stopped"),
+		agreement: utills.NewStream(seed, "agreement"),
+		plate:     utills.NewStream(seed, "plate"),
+		adversary: utills.NewStream(seed, "adversary"),
+		skew:      utills.NewStream(seed, "skew"),
+	}
+}
+
 // Function name: rpcConnectTo
-// opens a gRPC connection to the given IP and returns a client with timeout.
-func rpcConnectTo(ip string) (pb.VehicleServiceClient, *grpc.ClientConn, context.Context, context.CancelFunc, error) {
-	conn, err := grpc.Dial(
-		ip,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+// opens a gRPC connection from one vehicle to the given vehicle address and returns a client with timeout.
+// The sender travels in the request metadata, so the network faults of that link apply; 0 is the simulation itself.
+func rpcConnectTo(from int32, address int32) (pb.VehicleServiceClient, *grpc.ClientConn, context.Context, context.CancelFunc, error) {
+	var opts []grpc.DialOption
+	if RUN.CA != nil {
+		tlsConfig, err := RUN.CA.ClientConfig(from, address)
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("did not connect: %v", err)
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	}
+	conn, err := TRANSPORT.Dial(address, opts...)
 
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("did not connect: %v", err)
 	}
 
+	METRICS.CountRPC()
 	c := pb.NewVehicleServiceClient(conn)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
-	return c, conn, ctx, cancel, nil
+	return c, conn, transport.WithSource(ctx, from), cancel, nil
+}
+
+// Function name: releaseVehicle
+// Removes a vehicle that passed the intersection from the VEHICLES list and records its pass.
+func releaseVehicle(number int32, fallback bool) {
+	if err := SAFETY.Enter(number, DIRECTIONS[number]); err != nil {
+		log.Fatalf("%v", err)
+	}
+	VEHICLES = utills.RemoveValue(VEHICLES, number)
+	PASS_COUNT++
+	METRICS.Release(fallback, number)
+}
+
+// Function name: commitSchedule
+// Announces the decided schedule from the leader to every peer, including the leader's own server, with the
+// certificate of the leader's votes, and returns how many vehicles acknowledged it.
+func commitSchedule(schedule *pb.Schedule, leader *pb.Vehicle, peers []int32, certificate []*pb.SignedVote) int {
+	var acks int
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, k := range peers {
+		wg.Add(1)
+		go func(k int32) {
+			defer wg.Done()
+			client, conn, ctx, cancel, err := rpcConnectTo(schedule.Leader, k)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			defer cancel()
+
+			r, _ := client.CommitSchedule(ctx, &pb.Request{
+				Vehicle:     leader,
+				Term:        schedule.Term,
+				Schedule:    schedule,
+				Certificate: certificate,
+			})
+			if r != nil && r.Status == "acknowledged" {
+				mu.Lock()
+				acks++
+				mu.Unlock()
+			}
+		}(k)
+	}
+	wg.Wait()
+	return acks
+}
+
+// Function name: collectViews
+// Reads the final election state of every peer before its server is stopped.
+func collectViews(peers []int32) []verify.NodeView {
+	var views []verify.NodeView
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, k := range peers {
+		wg.Add(1)
+		go func(k int32) {
+			defer wg.Done()
+			client, conn, ctx, cancel, err := rpcConnectTo(0, k)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			defer cancel()
+
+			r, _ := client.GetState(ctx, &pb.Request{})
+			if r == nil || r.Vehicle == nil {
+				return
+			}
+			mu.Lock()
+			views = append(views, verify.NodeView{
+				Number:       r.Vehicle.Number,
+				Status:       r.Vehicle.ElectionStatus,
+				Leader:       r.Vehicle.Leader,
+				Term:         r.Vehicle.Term,
+				ReceiveVotes: r.Vehicle.ReceiveVotes,
+				ElectionTime: r.Vehicle.ElectionTime.AsTime(),
+			})
+			mu.Unlock()
+		}(k)
+	}
+	wg.Wait()
+	return views
 }
 
 // Function name: removeVehiclesIfQuorumReached
-// Removes the vehicle and its linked co-vehicles from the VEHICLES list.
-func removeVehiclesIfQuorumReached(vehicle *pb.Vehicle) bool {
+// Commits the passing group of the elected leader on its peers and returns the groups that may cross.
+// The group is the largest set of waiting CAVs, including the leader, whose movements are pairwise compatible.
+// With the schedule protocol the leader commits all waiting CAVs as repeated maximal groups instead,
+// and all of them pass in that order. Returns nil if the leader has already committed.
+func removeVehiclesIfQuorumReached(vehicle *pb.Vehicle, term int32, peers []int32, certificate []*pb.SignedVote) [][]int32 {
+	// several LeaderElection responses can complete the quorum at once; only the first one commits
+	commitMu.Lock()
+	defer commitMu.Unlock()
+	if vehicle.ElectionStatus == "Leader" {
+		return nil
+	}
+	vehicle.ElectionStatus = "Leader"
 
-	// 1) Remove the leader vehicle from the VEHICLES list
-	VEHICLES = utills.RemoveValue(VEHICLES, vehicle.Number)
-	PASS_COUNT++
+	METRICS.Leader(vehicle.Number)
 
-	if len(vehicle.Covehicle) > 0 {
-		// 2) Remove the first-level co-vehicle (if present)
-		firstCovehicle := vehicle.Covehicle[0]
-		if utills.Contains(VEHICLES, firstCovehicle.Number) {
-			VEHICLES = utills.RemoveValue(VEHICLES, firstCovehicle.Number)
-			PASS_COUNT++
+	groups := passingGroups(vehicle.Number, peers)
+	schedule := newSchedule(term, vehicle.Number, groups)
+	LEADERS = append(LEADERS, vehicle.Number)
+	METRICS.Commit(groups, commitSchedule(schedule, vehicle, peers, certificate))
+	return groups
+}
+
+// Function name: crossGroups
+// Lets the groups cross one after another: a group enters the intersection, needs crossTime to cross and
+// leaves it before the next group enters. Vehicles that have already passed are skipped.
+// Returns the time the crossings took.
+func crossGroups(groups [][]int32, crossTime time.Duration) time.Duration {
+	var crossed time.Duration
+	for _, group := range groups {
+		var crossing []int32
+		commitMu.Lock()
+		for _, number := range group {
+			if utills.Contains(VEHICLES, number) {
+				releaseVehicle(number, false)
+				crossing = append(crossing, number)
+			}
+		}
+		commitMu.Unlock()
+		if len(crossing) == 0 {
+			continue
 		}
 
-		// 3) If the first co-vehicle has sub-co-vehicles, process them
-		if firstCovehicle.Covehicle != nil && len(firstCovehicle.Covehicle) > 0 {
+		CLOCK.Sleep(crossTime)
+		SAFETY.Leave(crossing...)
+		crossed += crossTime
+	}
+	return crossed
+}
 
-			// 4) Remove all sub-level co-vehicles from the VEHICLES list
-			for _, subCovehicle := range vehicle.Covehicle[0].Covehicle {
-				if !utills.Contains(VEHICLES, subCovehicle.Number) {
-					continue
-				}
-				VEHICLES = utills.RemoveValue(VEHICLES, subCovehicle.Number)
-				PASS_COUNT++
+// Function name: passingGroups
+// Returns the groups the leader lets pass: the largest group of waiting peers, including the leader, whose
+// movements are pairwise compatible, or with the schedule protocol every waiting peer as repeated maximal groups.
+func passingGroups(leader int32, peers []int32) [][]int32 {
+	var waiting []int32
+	for _, k := range peers {
+		if utills.Contains(VEHICLES, k) {
+			waiting = append(waiting, k)
+		}
+	}
+	directions := reportedDirections(leader)
+	if PROTOCOL == "schedule" {
+		return direction.PartitionGroups(waiting, directions, leader)
+	}
+	return [][]int32{direction.MaximalGroup(waiting, directions, leader)}
+}
+
+// Function name: randomAgreement
+// Runs the commit-reveal RandomAgreement draw: every vehicle commits to a random value on every server,
+// then reveals it once all commitments are out. Each server combines the revealed values into the draw
+// that decides LeaderElection ties among candidates with equal ReceiveVotes. The draw is then closed on every
+// server; if any reveal was lost it is closed empty, so every server falls back to ElectionTime alike.
+// A server forgets the draw when it moves to a later term, so every term draws again.
+func randomAgreement(vehicles []int32, term int32) {
+	values := make(map[int32]int32)
+	for _, i := range vehicles {
+		values[i] = RNG.agreement.Int31()
+	}
+
+	var revealed atomic.Int64
+	for _, phase := range []string{"commit", "reveal"} {
+		var wg sync.WaitGroup
+		for _, i := range vehicles {
+			for _, j := range vehicles {
+				wg.Add(1)
+				go func(i int32, j int32) {
+					defer wg.Done()
+					client, conn, ctx, cancel, err := rpcConnectTo(i, j)
+					if err != nil {
+						return
+					}
+					defer conn.Close()
+					defer cancel()
+
+					req := &pb.Request{
+						Vehicle: &pb.Vehicle{Number: i, Address: i},
+						Phase:   phase,
+						Term:    term,
+					}
+					if phase == "commit" {
+						req.Commitment = server.Commitment(i, values[i])
+					} else {
+						req.RandomNumber = values[i]
+					}
+					r, _ := client.RandomAgreement(ctx, req)
+					if phase == "reveal" && r != nil && r.Status == "acknowledged" {
+						revealed.Add(1)
+					}
+				}(i, j)
+			}
+		}
+		wg.Wait()
+	}
+
+	var draw []int32
+	if revealed.Load() == int64(len(vehicles)*len(vehicles)) {
+		draw = vehicles
+	}
+	broadcast(vehicles, func(k int32) {
+		client, conn, ctx, cancel, err := rpcConnectTo(0, k)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		defer cancel()
+		_, _ = client.RandomAgreement(ctx, &pb.Request{Vehicle: &pb.Vehicle{}, Phase: "close", Roster: draw, Term: term})
+	})
+}
+
+// Function name: drawJitter
+// Draws the delay of the message from every vehicle to every other vehicle in the order of the slice,
+// before any of them is sent, so the delays of a seed do not depend on goroutine scheduling.
+func drawJitter(vehicles []int32) map[[2]int32]time.Duration {
+	jitter := make(map[[2]int32]time.Duration)
+	for _, i := range vehicles {
+		for _, j := range vehicles {
+			if i != j {
+				jitter[[2]int32{i, j}] = time.Duration(RNG.jitter.Intn(50000)) * time.Microsecond
 			}
 		}
 	}
-	return len(VEHICLES) == 0
+	return jitter
 }
 
 // Function name: selectRandomVehicles
 // Randomly selects n unique vehicles from the list.
-func selectRandomVehicles(vehicles []int, n int) []int {
+func selectRandomVehicles(r *rand.Rand, vehicles []int, n int) []int {
 	selected := make([]int, 0, n)
 	vehicleCopy := append([]int{}, vehicles...)
 	for i := 0; i < n; i++ {
-		idx := rand.Intn(len(vehicleCopy))
+		idx := r.Intn(len(vehicleCopy))
 		selected = append(selected, vehicleCopy[idx])
 		vehicleCopy = append(vehicleCopy[:idx], vehicleCopy[idx+1:]...)
 	}
@@ -87,18 +367,122 @@ func selectRandomVehicles(vehicles []int, n int) []int {
 }
 
 // Function name: main
-// Runs the full intersection consensus simulation and logs timing and consensus statistics.
+// Loads the scenario given on the command line (or the built-in default) and runs it.
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "sweep" {
+		runSweep(os.Args[2:])
+		return
+	}
+
+	scenarioPath := flag.String("scenario", "", "path to a JSON scenario file (built-in default scenario if empty)")
+	seed := flag.Int64("seed", 0, "random seed; overrides the scenario seed when non-zero")
+	flag.Parse()
+
+	scenario := config.DefaultScenario()
+	if *scenarioPath != "" {
+		loaded, err := config.LoadScenario(*scenarioPath)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		scenario = loaded
+	}
+	if *seed != 0 {
+		scenario.Seed = *seed
+	}
+
+	printResult(runSimulation(scenario))
+}
+
+// Function name: runSimulation
+// Runs the full intersection consensus simulation for one scenario and returns its timing and consensus statistics.
+func runSimulation(scenario *config.Scenario) *RunResult {
 
 	var totalConsensusCount = 0
 	var longTimeConsensusCount = 0
-	totalStartTime := time.Now()
+	CLOCK = clock.New(scenario.Clock)
+	RUN = server.NewRun()
+	RUN.Clock = CLOCK
+	TRANSPORT = transport.New(scenario.Transport, GO_SERVER_PORT)
+	if tracker, ok := CLOCK.(clock.Tracker); ok {
+		TRANSPORT = transport.NewTracked(TRANSPORT, tracker)
+	}
+	RUN.Transport = TRANSPORT
+	RUN.TieBreak = scenario.TieBreak
+	PROTOCOL = scenario.Protocol
+	totalStartTime := CLOCK.Now()
+	wallStartTime := time.Now()
+
+	VEHICLES = nil
+	PASS_COUNT = 0
 
 	// Adjustable simulation parameters
-	const NUMBER_OF_TOTAL_VEHICLES = 300
-	hvRatio := 0.1
-	line := 4
-	const VISION_TIME = 500
+	NUMBER_OF_TOTAL_VEHICLES := scenario.TotalVehicles
+	hvRatio := scenario.HVRatio
+	line := scenario.Lines
+	VISION_TIME := scenario.VisionTimeMs
+	RANDOM_PASS_TIME := scenario.PassTimeMs
+
+	seed := scenario.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	RNG = newRandomStreams(seed)
+	FAULTS = nil
+	if scenario.Network.Enabled() {
+		FAULTS = transport.NewFaulty(TRANSPORT, scenario.Network, CLOCK, utills.NewLockedStream(seed, "network"))
+		TRANSPORT = FAULTS
+		RUN.Transport = TRANSPORT
+	}
+	PARTITION = nil
+	if scenario.Partition.Enabled() {
+		PARTITION = transport.NewPartition(TRANSPORT, CLOCK)
+		TRANSPORT = PARTITION
+		RUN.Transport = TRANSPORT
+	}
+	RUN.StateDir = scenario.StateDir
+	if RUN.StateDir != "" {
+		if err := os.MkdirAll(RUN.StateDir, 0o755); err != nil {
+			log.Fatalf("state_dir: %v", err)
+		}
+		// state left by an earlier run belongs to other elections
+		if err := RUN.ClearState(); err != nil {
+			log.Fatalf("state_dir: %v", err)
+		}
+	}
+	if scenario.Security == "mtls" {
+		ca, err := identity.NewAuthority(scenario.CertDir)
+		if err != nil {
+			log.Fatalf("security: %v", err)
+		}
+		RUN.CA = ca
+	}
+	// BFT replicas always sign their Commit, so a commit certificate proves a decision
+	if scenario.Votes == "signed" || scenario.Consensus == "bft" {
+		RUN.Keys = identity.NewRing()
+		if scenario.DeterministicKeys {
+			RUN.Keys = identity.NewSeededRing(seed)
+		}
+	}
+	if scenario.Crash.Enabled() {
+		RUN.Crash = &server.CrashPlan{
+			Probability:  scenario.Crash.Probability,
+			Point:        scenario.Crash.Point,
+			Restart:      scenario.Crash.Restart,
+			RestartAfter: time.Duration(scenario.Crash.RestartAfterMs) * time.Millisecond,
+			Rand:         utills.NewLockedStream(seed, "crash"),
+		}
+	}
+	METRICS = metrics.NewRecorder(CLOCK)
+	SAFETY = verify.NewMonitor(CLOCK, scenario.Safety == "strict")
+	policy, err := quorum.New(scenario.Quorum)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	RUN.Quorum = policy
+	// elections every vehicle has taken part in without passing; a weighted quorum counts them
+	waited := make(map[int32]int)
+
+	result := &RunResult{Scenario: *scenario, Seed: seed}
 
 	totalVehicles := make([]int, NUMBER_OF_TOTAL_VEHICLES)
 	for i := 0; i < NUMBER_OF_TOTAL_VEHICLES; i++ {
@@ -106,72 +490,120 @@ func main() {
 	}
 
 	numHV := int(float64(NUMBER_OF_TOTAL_VEHICLES) * hvRatio)
-	hvVehicles := selectRandomVehicles(totalVehicles, numHV)
+	hvVehicles := selectRandomVehicles(RNG.hv, totalVehicles, numHV)
+	STRATEGIES, hvVehicles = selectAdversaries(RNG.adversary, totalVehicles, hvVehicles, scenario.Adversaries)
+	RUN.Strategies = STRATEGIES
+	REPLAYED_STAMPS = make(map[int32]*pb.Vehicle)
+	result.Adversaries = STRATEGIES
+
+	// every vehicle keeps its own local, possibly skewed, wall clock and logical clocks for the whole run
+	skews := drawSkews(RNG.skew, totalVehicles, scenario.ClockSkew)
+	RUN.ResetClocks(skews)
+	METRICS.SetSkews(skews)
+
+	PLATES = assignLicensePlates(RNG.plate, totalVehicles)
+	DIRECTIONS = make(map[int32]string)
 
 	for len(totalVehicles) > 0 {
 		totalConsensusCount++
-		TIMEOUT := time.Now()
+		TIMEOUT := CLOCK.Now()
 
 		// Select the number of vehicles participating in this consensus round.
 		// Test Mode A: Random count per round
-		var randomNum int32 = int32(rand.Intn(line*4) + 1)
+		var randomNum int32 = int32(RNG.round.Intn(line*4) + 1)
+
+		// Test Mode B: Fixed count per round
+		if scenario.RoundSize == "fixed" {
+			randomNum = int32(line * 4)
+		}
+
 		if randomNum > int32(len(totalVehicles)) {
 			randomNum = int32(len(totalVehicles))
 		}
 
-		// Test Mode B: Fixed count per round (uncomment to use)
-		// var TOTAL_VEHICLES int32 = randomNum
-		// var randomNum int32 = line*4
-		// if randomNum > int32(len(totalVehicles)) {
-		// 	randomNum = int32(len(totalVehicles))
-		// }
-
 		var TOTAL_VEHICLES int32 = randomNum
-		selectedVehicles := selectRandomVehicles(totalVehicles, int(TOTAL_VEHICLES))
+		selectedVehicles := selectRandomVehicles(RNG.round, totalVehicles, int(TOTAL_VEHICLES))
 
 		var RandomByzantine []int32
 
 		for i := 0; i < len(selectedVehicles); i++ {
 			VEHICLES = append(VEHICLES, int32(selectedVehicles[i]))
+			// a vehicle keeps its movement until it has passed
+			DIRECTIONS[int32(selectedVehicles[i])] = direction.Directions[RNG.direction.Intn(len(direction.Directions))]
 			if utills.ContainsInt(hvVehicles, selectedVehicles[i]) {
 				RandomByzantine = append(RandomByzantine, int32(selectedVehicles[i]))
 			}
 		}
 
+		METRICS.BeginRound(totalConsensusCount, VEHICLES, RandomByzantine)
+		SAFETY.BeginRound(totalConsensusCount)
+
+		// Cut the round's vehicles into groups that cannot reach each other until the partition heals.
+		if PARTITION != nil {
+			groups := partitionGroups(VEHICLES, scenario.Partition.Sizes)
+			var heal time.Time
+			if scenario.Partition.HealMs > 0 {
+				heal = TIMEOUT.Add(time.Duration(scenario.Partition.HealMs) * time.Millisecond)
+			}
+			PARTITION.Split(groups, TIMEOUT.Add(time.Duration(scenario.Partition.StartMs)*time.Millisecond), heal)
+			METRICS.Partition(groups)
+		}
+		var fallback = false
+
 		var STOP_VEHICLES_PASS_TIME int
+		// elections held in the round so far
+		var ELECTION int32
+		// time the elected groups of the round spent crossing
+		var CROSSING_TIME atomic.Int64
+		CROSS_TIME := time.Duration(scenario.CrossTimeMs) * time.Millisecond
+
+		// time the round has spent deciding: crossings do not count against T_vision
+		deciding := func() time.Duration {
+			return CLOCK.Now().Sub(TIMEOUT) - time.Duration(STOP_VEHICLES_PASS_TIME)*time.Millisecond - time.Duration(CROSSING_TIME.Load())
+		}
 
 		for len(VEHICLES) > 0 {
-			END_TIMEOUT := time.Now()
-			duration := END_TIMEOUT.Sub(TIMEOUT)
-
-			if len(VEHICLES) > 1 && duration-(time.Duration(STOP_VEHICLES_PASS_TIME)*time.Millisecond) >= time.Duration(VISION_TIME)*time.Millisecond {
+			if len(VEHICLES) > 1 && deciding() >= time.Duration(VISION_TIME)*time.Millisecond {
 				longTimeConsensusCount++
-				time.Sleep(time.Duration(VISION_TIME) * time.Millisecond)
+				fallback = true
+				CLOCK.Sleep(time.Duration(VISION_TIME) * time.Millisecond)
 
+				// Vision-based rule: CAVs pass in license-plate order, alone or in compatible groups.
+				var cavs []int32
 				for _, i := range VEHICLES {
-					if len(VEHICLES) > 0 && !utills.Contains(RandomByzantine, i) {
-						VEHICLES = utills.RemoveValue(VEHICLES, i)
-						PASS_COUNT++
+					if !utills.Contains(RandomByzantine, i) {
+						cavs = append(cavs, i)
 					}
 				}
+				for _, group := range visionPassingOrder(cavs, PLATES, DIRECTIONS, scenario.VisionRelease) {
+					METRICS.FallbackGroup(group)
+					for _, i := range group {
+						releaseVehicle(i, true)
+					}
+					CLOCK.Sleep(time.Duration(scenario.VisionPassTimeMs) * time.Millisecond)
+					SAFETY.Leave(group...)
+				}
 				break
 			}
 
 			var TOTAL_VEHICLES int32 = int32(len(VEHICLES))
 
-			// Quorum based on simple majority (more than half of TOTAL_VEHICLES)
-			var QUORUM int32 = (int32(TOTAL_VEHICLES))/2 + 1
-
-			// Quorum based on unanimity (all vehicles must agree)
-			// var QUORUM int32 = TOTAL_VEHICLES
+			// Vehicles of this election as the quorum policy sees them; a vehicle's weight grows with the elections it has waited.
+			QUORUM := quorum.Round{
+				Vehicles: append([]int32(nil), VEHICLES...),
+				HVs:      append([]int32(nil), RandomByzantine...),
+				Weights:  make(map[int32]int),
+			}
+			for _, i := range VEHICLES {
+				QUORUM.Weights[i] = 1 + waited[i]
+			}
 
 			if TOTAL_VEHICLES == 0 {
-				return
+				break
 			}
 
 			if TOTAL_VEHICLES == 1 {
-				PASS_COUNT++
-				VEHICLES = utills.RemoveValue(VEHICLES, 0)
+				crossGroups([][]int32{{VEHICLES[0]}}, CROSS_TIME)
 				break
 			}
 
@@ -188,28 +620,26 @@ func main() {
 				}
 
 				if VISION != 2 {
-					var RANDOM_PASS_TIME = 3000
 					STOP_VEHICLES_PASS_TIME += RANDOM_PASS_TIME
-					time.Sleep(time.Duration(RANDOM_PASS_TIME) * time.Millisecond)
+					CLOCK.Sleep(time.Duration(RANDOM_PASS_TIME) * time.Millisecond)
 				} else {
-					time.Sleep(VISION_TIME * time.Millisecond)
+					CLOCK.Sleep(time.Duration(VISION_TIME) * time.Millisecond)
 				}
 
-				if VEHICLES[0] == VEHICLES[1] {
-					VEHICLES = utills.RemoveValue(VEHICLES, VEHICLES[1])
-					PASS_COUNT++
-				} else {
-					VEHICLES = utills.RemoveValue(VEHICLES, VEHICLES[1])
-					PASS_COUNT++
-					VEHICLES = utills.RemoveValue(VEHICLES, VEHICLES[0])
-					PASS_COUNT++
+				// two conflicting movements cross one after the other
+				first, second := VEHICLES[1], VEHICLES[0]
+				groups := [][]int32{{first}, {second}}
+				if direction.Compatible(DIRECTIONS[first], DIRECTIONS[second]) {
+					groups = [][]int32{{first, second}}
 				}
+				crossGroups(groups, CROSS_TIME)
 				break
 			}
 
 			if TOTAL_VEHICLES >= 3 {
 				PASS_COUNT = 0
-				var directions = [12]string{"Rs", "Rl", "Rr", "Ls", "Ll", "Lr", "Ds", "Dl", "Dr", "Us", "Ul", "Ur"}
+				ELECTION++
+				METRICS.Election(VEHICLES)
 
 				var DirectionMap map[int32]string
 				var grpcServers []*grpc.Server
@@ -220,12 +650,12 @@ func main() {
 				DirectionMap = make(map[int32]string)
 
 				for _, i := range VEHICLES {
-					DirectionMap[i] = directions[rand.Intn(len(directions))]
+					DirectionMap[i] = DIRECTIONS[i]
 					var electionStatus = "Candidate"
 
 					go func(index int32, direction string, number int32) {
 						defer wg.Done()
-						grpcServer, _ := server.StartServer(index, direction, number, electionStatus)
+						grpcServer, _ := RUN.StartServer(index, direction, number, PLATES[number], electionStatus, int32(totalConsensusCount), ELECTION, QUORUM)
 						dataMu.Lock()
 						grpcServers = append(grpcServers, grpcServer)
 						dataMu.Unlock()
@@ -233,200 +663,282 @@ func main() {
 				}
 
 				wg.Wait()
-				wg.Add(int(TOTAL_VEHICLES))
-
-				serverData := make(map[int32]*pb.Vehicle)
-				var done = false
 
+				// Responsive CAVs: only they can vote, so a new term is useless once they cannot form a quorum.
+				// They are also the peers that receive the leader's schedule.
+				var peers []int32
 				for _, i := range VEHICLES {
+					if !utills.Contains(RandomByzantine, i) {
+						peers = append(peers, i)
+					}
+				}
+				LEADERS = nil
 
-					go func(i int32) {
-						defer wg.Done()
-						for _, j := range VEHICLES {
-							if i == j {
-								continue
-							}
-							if utills.Contains(RandomByzantine, j) {
-								continue
-							}
-							if utills.Contains(RandomByzantine, i) {
-								continue
-							}
-
-							wg.Add(1)
-							go func(j int32) {
-								defer wg.Done()
+				// The BFT election replaces the term loop below: it decides a schedule in one of its views or gives up.
+				if scenario.Consensus == "bft" {
+					expired := func() bool {
+						return deciding() >= time.Duration(VISION_TIME)*time.Millisecond
+					}
+					decided := bftElection(VEHICLES, peers, time.Duration(scenario.ViewTimeoutMs)*time.Millisecond, expired)
+					CROSSING_TIME.Add(int64(releaseDecided(decided, CROSS_TIME)))
+				}
 
-								if done {
-									return
+				// Run elections in increasing terms on the same servers until a leader is elected or T_vision runs out.
+				// A new term makes every server step down and vote again; requests still in flight from an
+				// earlier term are rejected by the servers instead of changing the new election.
+				for term := int32(1); scenario.Consensus == "crash"; term++ {
+					METRICS.Term()
+					// Draw the shared random value that breaks vote ties of the term among the responsive vehicles.
+					if scenario.TieBreak == "random" {
+						randomAgreement(peers, term)
+					}
+					receiveTally := quorum.NewTally()
+					electionTally := quorum.NewTally()
+					wg.Add(int(TOTAL_VEHICLES))
+
+					// Vote requests are jittered relative to the start of the election.
+					electionStart := CLOCK.Now()
+					jitter := drawJitter(VEHICLES)
+
+					serverData := make(map[int32]*pb.Vehicle)
+					// signed votes every candidate collected in this term, its quorum certificate
+					certificates := make(map[int32][]*pb.SignedVote)
+					var done atomic.Bool
+
+					for _, i := range VEHICLES {
+
+						go func(i int32) {
+							defer wg.Done()
+							for _, j := range QUORUM.Vehicles {
+								if i == j {
+									continue
+								}
+								if utills.Contains(RandomByzantine, j) {
+									continue
+								}
+								if utills.Contains(RandomByzantine, i) {
+									continue
 								}
 
-								time.Sleep(time.Duration(rand.Intn(50)) * time.Millisecond)
-
-								addr := fmt.Sprintf("localhost:%d", GO_SERVER_PORT+int(j))
-								client, conn, ctx, cancel, _ := rpcConnectTo(addr)
-								defer conn.Close()
-								defer cancel()
-
-								r, _ := client.ReceiveRequest(
-									ctx,
-									&pb.Request{
-										Vehicle: &pb.Vehicle{
-											Number:    i,
-											Address:   i,
-											Direction: DirectionMap[i],
-										},
-										Port:          fmt.Sprintf("%d", GO_SERVER_PORT+int(i)),
-										TotalVehicles: TOTAL_VEHICLES,
-									},
-								)
+								wg.Add(1)
+								go func(j int32) {
+									defer wg.Done()
 
-								if r == nil {
-									return
-								}
+									if done.Load() {
+										return
+									}
 
-								if done {
-									return
-								}
+									CLOCK.SleepUntil(electionStart.Add(jitter[[2]int32{i, j}]))
 
-								if r.Status == "acknowledged" {
-									dataMu.Lock()
-									defer dataMu.Unlock()
-									if done {
+									// a crashed vehicle sends nothing until its server is back
+									if RUN.Down(i) {
 										return
 									}
 
-									vehicle, exists := serverData[i]
+									client, conn, ctx, cancel, _ := rpcConnectTo(i, j)
+									defer conn.Close()
+									defer cancel()
 
-									if !exists {
-										vehicle = &pb.Vehicle{
-											Number:         i,
-											Address:        i,
-											ReceiveVotes:   0,
-											ElectionVote:   0,
-											ElectionStatus: "Candidate",
-										}
+									candidate := &pb.Vehicle{
+										Number:       i,
+										Address:      i,
+										Direction:    DirectionMap[i],
+										LicensePlate: PLATES[i],
 									}
+									// the vote request carries the candidate's clocks
+									RUN.Stamp(i, candidate)
+
+									r, _ := client.ReceiveRequest(
+										ctx,
+										&pb.Request{
+											Vehicle:       candidate,
+											Port:          fmt.Sprintf("%d", GO_SERVER_PORT+int(i)),
+											TotalVehicles: TOTAL_VEHICLES,
+											Term:          term,
+										},
+									)
 
-									if r.DirectionStatus == "True" {
-										var covehicles []*pb.Vehicle
-										covehicles = vehicle.Covehicle
-										var covehicleCheck = false
+									if r == nil {
+										return
+									}
 
-										for i := int32(0); i < int32(len(covehicles)); i++ {
-											if direction.DirectionBoolean(covehicles[i].Direction, r.ResponseDirection) {
-												covehicleCheck = true
+									if done.Load() {
+										return
+									}
 
-												if covehicles[i].Covehicle == nil {
-													covehicles[i].Covehicle = []*pb.Vehicle{}
-												}
-												covehicles[i].Covehicle = append(covehicles[i].Covehicle, r.Vehicle)
-											}
+									if r.Status == "acknowledged" {
+										dataMu.Lock()
+										if done.Load() {
+											dataMu.Unlock()
+											return
 										}
 
-										if covehicleCheck == false {
-											covehicles = append(covehicles, r.Vehicle)
-											vehicle.Covehicle = covehicles
-										}
-									}
+										vehicle, exists := serverData[i]
 
-									vehicle.ElectionTime = pbtimestamp.Now()
+										if !exists {
+											vehicle = &pb.Vehicle{
+												Number:         i,
+												Address:        i,
+												ReceiveVotes:   0,
+												ElectionVote:   0,
+												ElectionStatus: "Candidate",
+												Term:           term,
+											}
+										}
 
-									go func(vehicle *pb.Vehicle) {
-										if done {
-											return
+										RUN.Observe(i, r.Vehicle)
+										stampElection(i, vehicle)
+										if r.Vote != nil {
+											certificates[i] = append(certificates[i], r.Vote)
 										}
 
+										// the count and the certificate that proves it are taken together
 										vehicle.ReceiveVotes++
-										addr := fmt.Sprintf("localhost:%d", GO_SERVER_PORT+int(vehicle.Address))
-										client, conn, ctx, cancel, _ := rpcConnectTo(addr)
+										claim := proto.Clone(vehicle).(*pb.Vehicle)
+										certificate := append([]*pb.SignedVote(nil), certificates[i]...)
 
-										defer conn.Close()
-										defer cancel()
+										go func(claim *pb.Vehicle) {
+											if done.Load() {
+												return
+											}
 
-										_, _ = client.UpdateVoteCount(ctx, &pb.Request{
-											Vehicle: vehicle,
-										})
+											client, conn, ctx, cancel, _ := rpcConnectTo(i, claim.Address)
 
-									}(vehicle)
+											defer conn.Close()
+											defer cancel()
 
-									serverData[i] = vehicle
+											_, _ = client.UpdateVoteCount(ctx, &pb.Request{
+												Vehicle:     claim,
+												Term:        term,
+												Certificate: certificate,
+											})
 
-									if vehicle.ReceiveVotes >= QUORUM-1 {
-										var wg sync.WaitGroup
-										for _, k := range VEHICLES {
-											if k == i {
-												continue
-											}
-											if utills.Contains(RandomByzantine, k) {
-												continue
-											}
+										}(claim)
 
-											wg.Add(1)
-											go func(k int32) {
-												defer wg.Done()
-												if done {
-													return
+										serverData[i] = vehicle
+										// the LeaderElection responses below take the lock again
+										dataMu.Unlock()
+
+										if policy.Reached(receiveTally.Add(i, j), QUORUM) {
+											var wg sync.WaitGroup
+											for _, k := range QUORUM.Vehicles {
+												if k == i {
+													continue
 												}
-												addr := fmt.Sprintf("localhost:%d", GO_SERVER_PORT+int(k))
-												client, conn, ctx, cancel, err := rpcConnectTo(addr)
-												if err != nil {
-
-													return
+												if utills.Contains(RandomByzantine, k) {
+													continue
 												}
-												defer conn.Close()
-												defer cancel()
 
-												r, err = client.LeaderElection(ctx, &pb.Request{
-													Vehicle: vehicle,
-												})
+												wg.Add(1)
+												go func(k int32) {
+													defer wg.Done()
+													if done.Load() || RUN.Down(i) {
+														return
+													}
+													client, conn, ctx, cancel, err := rpcConnectTo(i, k)
+													if err != nil {
 
-												if r == nil {
-													return
-												}
+														return
+													}
+													defer conn.Close()
+													defer cancel()
 
-												if done {
-													return
-												}
+													r, _ := client.LeaderElection(ctx, &pb.Request{
+														Vehicle:     claimedVotes(claim),
+														Term:        term,
+														Certificate: certificate,
+													})
 
-												if r.Status == "acknowledged" {
-													vehicle.ElectionVote++
-													if vehicle.ElectionVote >= QUORUM-1 && vehicle.ElectionStatus == "Candidate" {
-														removeVehiclesIfQuorumReached(vehicle)
-														done = true
+													if r == nil {
 														return
 													}
-												} else if r.Status == "ignored" {
-													serverData[i] = r.Vehicle
-												}
 
-											}(k)
-										}
+													dataMu.Lock()
+													if done.Load() {
+														dataMu.Unlock()
+														return
+													}
 
-										wg.Wait()
-									}
-								} else {
-									dataMu.Lock()
-									vehicle, exists := serverData[i]
-									if !exists {
-										vehicle = &pb.Vehicle{
-											Number:         i,
-											Address:        i,
-											ReceiveVotes:   0,
-											ElectionVote:   0,
-											ElectionStatus: "Candidate",
+													if r.Status == "acknowledged" {
+														vehicle.ElectionVote++
+														if policy.Reached(electionTally.Add(i, k), QUORUM) && vehicle.ElectionStatus == "Candidate" {
+															groups := removeVehiclesIfQuorumReached(vehicle, term, peers, certificate)
+															done.Store(true)
+															dataMu.Unlock()
+															CROSSING_TIME.Add(int64(crossGroups(groups, CROSS_TIME)))
+															return
+														}
+													} else if r.Status == "ignored" {
+														serverData[i] = r.Vehicle
+													}
+													dataMu.Unlock()
+
+												}(k)
+											}
+
+											wg.Wait()
 										}
-										serverData[i] = vehicle
+									} else {
+										dataMu.Lock()
+										vehicle, exists := serverData[i]
+										if !exists {
+											vehicle = &pb.Vehicle{
+												Number:         i,
+												Address:        i,
+												ReceiveVotes:   0,
+												ElectionVote:   0,
+												ElectionStatus: "Candidate",
+												Term:           term,
+											}
+											serverData[i] = vehicle
+										}
+										dataMu.Unlock()
 									}
-									dataMu.Unlock()
-								}
 
-							}(j)
-						}
-					}(i)
-				}
+								}(j)
+							}
+						}(i)
+					}
 
-				wg.Wait()
+					wg.Wait()
+
+					if done.Load() || !policy.Reached(peers, QUORUM) {
+						break
+					}
+					if deciding() >= time.Duration(VISION_TIME)*time.Millisecond {
+						break
+					}
+				}
+				// Cross-check the final view of every vehicle before the servers are stopped.
+				// Only honest vehicles are bound to agree; a malicious one may claim anything.
+				if FAULTS != nil {
+					FAULTS.Flush()
+				}
+				var honest []int32
+				for _, k := range peers {
+					if STRATEGIES[k] == "" {
+						honest = append(honest, k)
+					}
+				}
+				verdict := verify.CheckAgreement(totalConsensusCount, LEADERS, collectViews(honest))
+				result.Agreement = append(result.Agreement, verdict)
+				METRICS.Agreement(verdict.Pass)
+
+				METRICS.AddStaleRejections(RUN.TakeStaleRejections())
+				METRICS.AddDoubleVotes(RUN.TakeDoubleVotes())
+				METRICS.AddInvalidCerts(RUN.TakeInvalidCertificates())
+				METRICS.AddSpoofed(RUN.TakeSpoofed())
+				for _, tie := range RUN.TakeTies() {
+					METRICS.Tie(tie.Winner, tie.Loser)
+				}
+				if PARTITION != nil {
+					METRICS.AddPartitioned(PARTITION.TakeBlocked())
+				}
+				if FAULTS != nil {
+					faults := FAULTS.Take()
+					METRICS.AddFaults(faults.Dropped, faults.Duplicated, faults.Reordered)
+				}
+				METRICS.AddRandomTieBreaks(RUN.TakeRandomTieBreaks())
 				dataMu.Lock()
 
 				for _, server := range grpcServers {
@@ -436,31 +948,51 @@ func main() {
 				}
 
 				dataMu.Unlock()
+				METRICS.AddCrashes(RUN.StopRecovered())
+				if err := RUN.ClearState(); err != nil {
+					log.Fatalf("state_dir: %v", err)
+				}
 
-				var NUMBER_OF_PASS_STOP_VEHICLES = rand.Intn(len(RandomByzantine) + 1)
+				// the elected groups have crossed; the stopped HVs that move now do not coordinate and cross together
+				var NUMBER_OF_PASS_STOP_VEHICLES = RNG.stopped.Intn(len(RandomByzantine) + 1)
+				var stopped []int32
 
 				for i := 1; i <= NUMBER_OF_PASS_STOP_VEHICLES; i++ {
-					var PASS_STOP_VEHICLES = RandomByzantine[rand.Intn(len(RandomByzantine))]
-					VEHICLES = utills.RemoveValue(VEHICLES, int32(PASS_STOP_VEHICLES))
-					PASS_COUNT++
+					var PASS_STOP_VEHICLES = RandomByzantine[RNG.stopped.Intn(len(RandomByzantine))]
+					stopped = append(stopped, int32(PASS_STOP_VEHICLES))
 					RandomByzantine = utills.RemoveValue(RandomByzantine, int32(PASS_STOP_VEHICLES))
 				}
 
 				if NUMBER_OF_PASS_STOP_VEHICLES >= 1 {
-					var RANDOM_PASS_TIME = 3000
 					STOP_VEHICLES_PASS_TIME += RANDOM_PASS_TIME
-					time.Sleep(time.Duration(RANDOM_PASS_TIME) * time.Millisecond)
+					crossGroups([][]int32{stopped}, time.Duration(RANDOM_PASS_TIME)*time.Millisecond)
+				}
+
+				// the vehicles that did not pass wait for the next election
+				for _, i := range VEHICLES {
+					waited[i]++
 				}
 			}
 		}
 		totalVehicles = utills.Difference(totalVehicles, selectedVehicles)
 
+		METRICS.EndRound(fallback)
 	}
-	totalEndTime := time.Now()
+	totalEndTime := CLOCK.Now()
 	duration := totalEndTime.Sub(totalStartTime)
 
-	fmt.Printf("Total consensus duration: %v\n", duration)
-	fmt.Printf("Number of consensus rounds: %v\n", totalConsensusCount)
-	fmt.Printf("Rounds exceeding %v ms: %v\n", VISION_TIME, longTimeConsensusCount)
-	fmt.Printf("Vision-system consensus percentage: %v%%\n", longTimeConsensusCount*100/totalConsensusCount)
+	result.DurationMs = milliseconds(duration)
+	result.WallTimeMs = milliseconds(time.Since(wallStartTime))
+	result.Rounds = totalConsensusCount
+	result.FallbackRounds = longTimeConsensusCount
+	result.FallbackPct = float64(longTimeConsensusCount) * 100 / float64(totalConsensusCount)
+	result.RoundResults = METRICS.Rounds()
+	result.Vehicles = METRICS.Vehicles()
+	result.Violations = SAFETY.Violations()
+	for _, v := range result.Violations {
+		result.RoundResults[v.Round-1].SafetyViolations++
+	}
+	result.Summary = metrics.Summarize(result.RoundResults, result.Vehicles)
+	result.Liveness = verify.CheckLiveness(result.Vehicles, result.DurationMs, float64(scenario.LivenessBoundMs))
+	return result
 }
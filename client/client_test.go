@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	config "main/config"
+)
+
+func TestRunSimulationSeeded(t *testing.T) {
+	tests := []struct {
+		name      string
+		consensus string
+		protocol  string
+	}{
+		{"crash leader", "crash", "leader"},
+		{"crash schedule", "crash", "schedule"},
+		{"bft leader", "bft", "leader"},
+		{"bft schedule", "bft", "schedule"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scenario := config.DefaultScenario()
+			scenario.Name = tt.name
+			scenario.TotalVehicles = 12
+			// stopped HVs do not coordinate and may cross with a conflicting movement, which the monitor reports
+			scenario.HVRatio = 0
+			scenario.Lines = 2
+			scenario.Seed = 7
+			scenario.Clock = "virtual"
+			scenario.Transport = "memory"
+			scenario.Consensus = tt.consensus
+			scenario.Protocol = tt.protocol
+			if err := scenario.Validate(); err != nil {
+				t.Fatal(err)
+			}
+
+			var outputs []string
+			for run := 0; run < 2; run++ {
+				sc := *scenario
+				res := runSimulation(&sc)
+				if res.Summary.SafetyViolations != 0 {
+					t.Fatalf("run %d: %d safety violations: %v", run, res.Summary.SafetyViolations, res.Violations)
+				}
+				if res.Summary.AgreementFailures != 0 {
+					t.Fatalf("run %d: %d agreement failures", run, res.Summary.AgreementFailures)
+				}
+				// only the wall-clock time may differ between two runs of a seed
+				res.WallTimeMs = 0
+				data, err := json.Marshal(res)
+				if err != nil {
+					t.Fatal(err)
+				}
+				outputs = append(outputs, string(data))
+			}
+			if outputs[0] != outputs[1] {
+				t.Fatalf("two runs of seed %d differ:\n%s\n%s", scenario.Seed, outputs[0], outputs[1])
+			}
+		})
+	}
+}
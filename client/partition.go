@@ -0,0 +1,30 @@
+package main
+
+import "math"
+
+// Function name: partitionGroups
+// Splits the vehicles of a round into consecutive groups with the given shares.
+// If the shares add up to 1 the last group also takes the vehicles left over by rounding; otherwise the
+// vehicles after the last group stay in no group, and the partition does not cut them off.
+func partitionGroups(vehicles []int32, sizes []float64) [][]int32 {
+	var sum float64
+	for _, share := range sizes {
+		sum += share
+	}
+	complete := math.Abs(sum-1) < 1e-6
+
+	groups := make([][]int32, len(sizes))
+	next := 0
+	for g, share := range sizes {
+		n := int(math.Round(share * float64(len(vehicles))))
+		if complete && g == len(sizes)-1 {
+			n = len(vehicles) - next
+		}
+		if next+n > len(vehicles) {
+			n = len(vehicles) - next
+		}
+		groups[g] = append([]int32(nil), vehicles[next:next+n]...)
+		next += n
+	}
+	return groups
+}
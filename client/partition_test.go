@@ -0,0 +1,27 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPartitionGroups(t *testing.T) {
+	vehicles := []int32{1, 2, 3, 4, 5, 6, 7}
+	tests := []struct {
+		name  string
+		sizes []float64
+		want  [][]int32
+	}{
+		{"shares add up to 1", []float64{0.6, 0.4}, [][]int32{{1, 2, 3, 4}, {5, 6, 7}}},
+		{"rounding remainder goes last", []float64{0.3, 0.3, 0.4}, [][]int32{{1, 2}, {3, 4}, {5, 6, 7}}},
+		{"leftovers stay uncut", []float64{0.3, 0.3}, [][]int32{{1, 2}, {3, 4}}},
+		{"capped at the round size", []float64{0.24, 0.24, 0.24, 0.24}, [][]int32{{1, 2}, {3, 4}, {5, 6}, {7}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := partitionGroups(vehicles, tt.sizes); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("partitionGroups = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	config "main/config"
+	metrics "main/metrics"
+	verify "main/verify"
+)
+
+// RunResult is the outcome of one simulation run.
+type RunResult struct {
+	Scenario       config.Scenario       `json:"scenario"`
+	Seed           int64                 `json:"seed"`
+	Replication    int                   `json:"replication"`
+	DurationMs     float64               `json:"duration_ms"` // simulated time of the whole run
+	WallTimeMs     float64               `json:"wall_time_ms"`
+	Rounds         int                   `json:"rounds"`
+	FallbackRounds int                   `json:"fallback_rounds"`
+	FallbackPct    float64               `json:"fallback_pct"`
+	Summary        metrics.Summary       `json:"summary"`
+	RoundResults   []metrics.Round       `json:"round_results"`
+	Vehicles       []metrics.Vehicle     `json:"vehicles"`
+	Violations     []verify.Violation    `json:"safety_violations"`
+	Agreement      []verify.Verdict      `json:"agreement"` // one verdict per election
+	Liveness       verify.LivenessReport `json:"liveness"`
+	Adversaries    map[int32]string      `json:"adversaries"` // strategy of every malicious vehicle
+}
+
+// Function name: milliseconds
+// Converts a duration to fractional milliseconds for the result files.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Function name: printResult
+// Logs the summary of one run in the original text format, followed by the latency distributions.
+func printResult(res *RunResult) {
+	fmt.Printf("Scenario: %s\n", res.Scenario.Name)
+	fmt.Printf("Total consensus duration: %v\n", time.Duration(res.DurationMs*float64(time.Millisecond)))
+	if res.Scenario.Clock == "virtual" {
+		fmt.Printf("Wall-clock time: %v\n", time.Duration(res.WallTimeMs*float64(time.Millisecond)))
+	}
+	fmt.Printf("Quorum policy: %s\n", res.Scenario.Quorum)
+	if res.Scenario.Consensus == "bft" {
+		fmt.Printf("Consensus: bft (2f+1 of every roster, view timeout %d ms)\n", res.Scenario.ViewTimeoutMs)
+	}
+	if len(res.Adversaries) > 0 {
+		counts := make(map[string]int)
+		for _, strategy := range res.Adversaries {
+			counts[strategy]++
+		}
+		var parts []string
+		for _, strategy := range config.Strategies {
+			if counts[strategy] > 0 {
+				parts = append(parts, fmt.Sprintf("%s: %d", strategy, counts[strategy]))
+			}
+		}
+		fmt.Printf("Malicious vehicles: %d (%s)\n", len(res.Adversaries), strings.Join(parts, ", "))
+	}
+	fmt.Printf("Number of consensus rounds: %v\n", res.Rounds)
+	fmt.Printf("Rounds exceeding %v ms: %v\n", res.Scenario.VisionTimeMs, res.FallbackRounds)
+	fmt.Printf("Vision-system consensus percentage: %v%%\n", res.FallbackRounds*100/res.Rounds)
+
+	printDistribution("Time to leader (ms)", res.Summary.TimeToLeader)
+	printDistribution("Round duration (ms)", res.Summary.RoundDuration)
+	printDistribution("RPCs per round", res.Summary.RPCsPerRound)
+	printDistribution("Election terms per round", res.Summary.TermsPerRound)
+	printDistribution("Arrival-to-pass delay (ms)", res.Summary.PassDelay)
+	printHistogram(res.Summary.PassDelay)
+	if len(res.Adversaries) > 0 {
+		printStrategyDelays(res)
+	}
+	if res.Scenario.TieBreak == "random" {
+		fmt.Printf("Ties decided by RandomAgreement: %d (in %d of %d rounds)\n",
+			res.Summary.RandomTieBreaks, res.Summary.TieBreakRounds, res.Rounds)
+	}
+	if res.Summary.Ties > 0 || res.Scenario.ClockSkew.Enabled() {
+		fmt.Printf("Ties on ReceiveVotes: %d, decided by %s, %d won by the candidate with the faster clock\n",
+			res.Summary.Ties, res.Scenario.TieBreak, res.Summary.FasterClockWins)
+	}
+	fmt.Printf("Delay fairness (Jain's index): %.3f\n", res.Summary.DelayFairness)
+	if res.Scenario.Network.Enabled() {
+		fmt.Printf("Network faults: %d dropped, %d duplicated, %d reordered\n",
+			res.Summary.Dropped, res.Summary.Duplicated, res.Summary.Reordered)
+	}
+	if res.Scenario.Partition.Enabled() {
+		fmt.Printf("Messages lost to the partition: %d\n", res.Summary.Partitioned)
+	}
+	if res.Scenario.Crash.Enabled() {
+		fmt.Printf("Crashed CAV servers: %d (%d restarted, %s, point %s)\n",
+			res.Summary.Crashes, res.Summary.Restarts, res.Scenario.Crash.Restart, res.Scenario.Crash.Point)
+	}
+	if res.Summary.DoubleVotes > 0 || res.Scenario.StateDir != "" {
+		fmt.Printf("Double votes: %d\n", res.Summary.DoubleVotes)
+	}
+	if res.Scenario.Votes == "signed" {
+		fmt.Printf("Vote claims rejected for an invalid certificate: %d\n", res.Summary.InvalidCerts)
+	} else if res.Scenario.Consensus == "bft" {
+		fmt.Printf("BFT Prepare, Commit and decisions rejected for an invalid signature: %d\n", res.Summary.InvalidCerts)
+	}
+	if res.Scenario.Security == "mtls" {
+		fmt.Printf("Requests rejected for a spoofed vehicle number: %d\n", res.Summary.Spoofed)
+	}
+	if res.Summary.StaleRejected > 0 {
+		fmt.Printf("Requests rejected for a stale term: %d\n", res.Summary.StaleRejected)
+	}
+	fmt.Printf("Agreement: %d of %d rounds with an election passed\n",
+		res.Summary.AgreementRounds-res.Summary.AgreementFailures, res.Summary.AgreementRounds)
+	printed := 0
+	for _, v := range res.Agreement {
+		for _, problem := range v.Problems {
+			if printed < 5 {
+				fmt.Printf("  round %d: %s\n", v.Round, problem)
+			}
+			printed++
+		}
+	}
+	if printed > 5 {
+		fmt.Printf("  ... %d more\n", printed-5)
+	}
+	undecided := 0
+	for _, v := range res.Agreement {
+		undecided += len(v.Undecided)
+	}
+	if undecided > 0 {
+		fmt.Printf("Vehicles that ended an election without a leader: %d\n", undecided)
+	}
+	fmt.Printf("Safety violations: %d\n", res.Summary.SafetyViolations)
+	for i, v := range res.Violations {
+		if i == 5 {
+			fmt.Printf("  ... %d more\n", len(res.Violations)-i)
+			break
+		}
+		fmt.Printf("  %v\n", v)
+	}
+	printWait("Worst-case wait", res.Liveness.Worst, res.Seed)
+	fmt.Printf("Vehicles over the %.0f ms wait bound: %d\n", res.Liveness.BoundMs, len(res.Liveness.Starved))
+	if res.Summary.Unreleased > 0 {
+		fmt.Printf("Vehicles that never passed: %d\n", res.Summary.Unreleased)
+	}
+	fmt.Printf("Seed: %d\n", res.Seed)
+}
+
+// Function name: printWait
+// Logs one vehicle's wait with the rounds, the elections it waited through and the seed that reproduce it.
+func printWait(name string, w verify.Wait, seed int64) {
+	kind := "CAV"
+	if w.HV {
+		kind = "HV"
+	}
+	passed := fmt.Sprintf("passed in round %d", w.PassRound)
+	if w.PassRound == 0 {
+		passed = "never passed"
+	}
+	fmt.Printf("%s: %.1f ms, vehicle %d (%s), first round %d, %s after %d election(s), seed %d\n",
+		name, w.WaitMs, w.Vehicle, kind, w.FirstRound, passed, w.Elections, seed)
+}
+
+// Function name: printStrategyDelays
+// Logs the mean arrival-to-pass delay of the honest vehicles and of the vehicles of every strategy.
+func printStrategyDelays(res *RunResult) {
+	sums := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, v := range res.Vehicles {
+		if v.DelayMs < 0 {
+			continue
+		}
+		strategy := res.Adversaries[v.Number]
+		sums[strategy] += v.DelayMs
+		counts[strategy]++
+	}
+	parts := []string{fmt.Sprintf("honest %.1f", sums[""]/float64(max(counts[""], 1)))}
+	for _, strategy := range config.Strategies {
+		if counts[strategy] > 0 {
+			parts = append(parts, fmt.Sprintf("%s %.1f", strategy, sums[strategy]/float64(counts[strategy])))
+		}
+	}
+	fmt.Printf("Mean delay by strategy (ms): %s\n", strings.Join(parts, ", "))
+}
+
+// Function name: printDistribution
+// Logs the sample count and percentiles of a distribution on one line.
+func printDistribution(name string, d metrics.Distribution) {
+	if d.Count == 0 {
+		fmt.Printf("%s: no samples\n", name)
+		return
+	}
+	fmt.Printf("%s: n=%d mean=%.1f p50=%.1f p90=%.1f p99=%.1f max=%.1f\n",
+		name, d.Count, d.Mean, d.P50, d.P90, d.P99, d.Max)
+}
+
+// Function name: printHistogram
+// Logs a text bar chart of the histogram buckets.
+func printHistogram(d metrics.Distribution) {
+	if d.Count == 0 {
+		return
+	}
+	lower := 0.0
+	for _, b := range d.Histogram {
+		label := fmt.Sprintf("> %g", lower)
+		if b.UpperBound >= 0 {
+			label = fmt.Sprintf("%g-%g", lower, b.UpperBound)
+			lower = b.UpperBound
+		}
+		fmt.Printf("  %12s | %-40s %d\n", label, strings.Repeat("#", b.Count*40/d.Count), b.Count)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+
+	config "main/config"
+)
+
+// Function name: drawSkews
+// Draws the local clock skew of every vehicle uniformly from [-max_ms, max_ms] and applies the fixed
+// skews of the listed vehicles. Vehicles without skew are left out.
+func drawSkews(r *rand.Rand, vehicles []int, skew config.ClockSkew) map[int32]time.Duration {
+	skews := make(map[int32]time.Duration)
+	for _, v := range vehicles {
+		if skew.MaxMs > 0 {
+			skews[int32(v)] = time.Duration(r.Intn(2*skew.MaxMs+1)-skew.MaxMs) * time.Millisecond
+		}
+	}
+	for number, ms := range skew.Vehicles {
+		skews[number] = time.Duration(ms) * time.Millisecond
+	}
+	return skews
+}
@@ -0,0 +1,523 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	config "main/config"
+	metrics "main/metrics"
+	utills "main/utills"
+)
+
+// column is one field of a CSV result file.
+type column[T any] struct {
+	name  string
+	value func(T) string
+}
+
+// runRow is one row of runs.csv.
+type runRow struct {
+	run int
+	res *RunResult
+}
+
+// roundRow is one row of rounds.csv.
+type roundRow struct {
+	run   int
+	res   *RunResult
+	round metrics.Round
+}
+
+// vehicleRow is one row of vehicles.csv.
+type vehicleRow struct {
+	run     int
+	res     *RunResult
+	vehicle metrics.Vehicle
+}
+
+// axis is one parameter of a sweep: the values of its flag and how one of them is set on a scenario.
+type axis struct {
+	flag  string                           // flag of the axis, without the dash
+	count int                              // number of values; 1 keeps the base scenario's value
+	given bool                             // whether the flag was set
+	set   func(sc *config.Scenario, i int) // sets the i-th value on the scenario
+	inert func(sc *config.Scenario) string // why the axis has no effect on the scenario, "" if it has one
+	err   error                            // error parsing the flag
+}
+
+// Columns of runs.csv, one row per run.
+var runColumns = []column[runRow]{
+	{"run", func(r runRow) string { return strconv.Itoa(r.run) }},
+	{"scenario", func(r runRow) string { return r.res.Scenario.Name }},
+	{"hv_ratio", func(r runRow) string { return formatFloat(r.res.Scenario.HVRatio) }},
+	{"lines", func(r runRow) string { return strconv.Itoa(r.res.Scenario.Lines) }},
+	{"vision_time_ms", func(r runRow) string { return strconv.Itoa(r.res.Scenario.VisionTimeMs) }},
+	{"round_size", func(r runRow) string { return r.res.Scenario.RoundSize }},
+	{"quorum", func(r runRow) string { return r.res.Scenario.Quorum }},
+	{"tie_break", func(r runRow) string { return r.res.Scenario.TieBreak }},
+	{"protocol", func(r runRow) string { return r.res.Scenario.Protocol }},
+	{"consensus", func(r runRow) string { return r.res.Scenario.Consensus }},
+	{"replication", func(r runRow) string { return strconv.Itoa(r.res.Replication) }},
+	{"seed", func(r runRow) string { return strconv.FormatInt(r.res.Seed, 10) }},
+	{"rounds", func(r runRow) string { return strconv.Itoa(r.res.Rounds) }},
+	{"fallback_rounds", func(r runRow) string { return strconv.Itoa(r.res.FallbackRounds) }},
+	{"fallback_pct", func(r runRow) string { return formatFloat(r.res.FallbackPct) }},
+	{"duration_ms", func(r runRow) string { return formatFloat(r.res.DurationMs) }},
+	{"wall_time_ms", func(r runRow) string { return formatFloat(r.res.WallTimeMs) }},
+	{"time_to_leader_p50_ms", func(r runRow) string { return formatFloat(r.res.Summary.TimeToLeader.P50) }},
+	{"time_to_leader_p90_ms", func(r runRow) string { return formatFloat(r.res.Summary.TimeToLeader.P90) }},
+	{"time_to_leader_p99_ms", func(r runRow) string { return formatFloat(r.res.Summary.TimeToLeader.P99) }},
+	{"delay_p50_ms", func(r runRow) string { return formatFloat(r.res.Summary.PassDelay.P50) }},
+	{"delay_p90_ms", func(r runRow) string { return formatFloat(r.res.Summary.PassDelay.P90) }},
+	{"delay_p99_ms", func(r runRow) string { return formatFloat(r.res.Summary.PassDelay.P99) }},
+	{"delay_max_ms", func(r runRow) string { return formatFloat(r.res.Summary.PassDelay.Max) }},
+	{"rpcs_mean", func(r runRow) string { return formatFloat(r.res.Summary.RPCsPerRound.Mean) }},
+	{"unreleased", func(r runRow) string { return strconv.Itoa(r.res.Summary.Unreleased) }},
+	{"random_tie_breaks", func(r runRow) string { return strconv.Itoa(r.res.Summary.RandomTieBreaks) }},
+	{"terms_mean", func(r runRow) string { return formatFloat(r.res.Summary.TermsPerRound.Mean) }},
+	{"stale_rejected", func(r runRow) string { return strconv.Itoa(r.res.Summary.StaleRejected) }},
+	{"drop", func(r runRow) string { return formatFloat(r.res.Scenario.Network.Drop) }},
+	{"crash_probability", func(r runRow) string { return formatFloat(r.res.Scenario.Crash.Probability) }},
+	{"adversaries", func(r runRow) string { return strconv.Itoa(len(r.res.Adversaries)) }},
+	{"strategy", func(r runRow) string { return r.res.Scenario.Adversaries.Strategy }},
+	{"skew_max_ms", func(r runRow) string { return strconv.Itoa(r.res.Scenario.ClockSkew.MaxMs) }},
+	{"ties", func(r runRow) string { return strconv.Itoa(r.res.Summary.Ties) }},
+	{"faster_clock_wins", func(r runRow) string { return strconv.Itoa(r.res.Summary.FasterClockWins) }},
+	{"delay_fairness", func(r runRow) string { return formatFloat(r.res.Summary.DelayFairness) }},
+	{"dropped", func(r runRow) string { return strconv.Itoa(r.res.Summary.Dropped) }},
+	{"duplicated", func(r runRow) string { return strconv.Itoa(r.res.Summary.Duplicated) }},
+	{"reordered", func(r runRow) string { return strconv.Itoa(r.res.Summary.Reordered) }},
+	{"partitioned", func(r runRow) string { return strconv.Itoa(r.res.Summary.Partitioned) }},
+	{"crashes", func(r runRow) string { return strconv.Itoa(r.res.Summary.Crashes) }},
+	{"restarts", func(r runRow) string { return strconv.Itoa(r.res.Summary.Restarts) }},
+	{"double_votes", func(r runRow) string { return strconv.Itoa(r.res.Summary.DoubleVotes) }},
+	{"invalid_certs", func(r runRow) string { return strconv.Itoa(r.res.Summary.InvalidCerts) }},
+	{"spoofed", func(r runRow) string { return strconv.Itoa(r.res.Summary.Spoofed) }},
+	{"safety_violations", func(r runRow) string { return strconv.Itoa(r.res.Summary.SafetyViolations) }},
+	{"agreement_rounds", func(r runRow) string { return strconv.Itoa(r.res.Summary.AgreementRounds) }},
+	{"agreement_failures", func(r runRow) string { return strconv.Itoa(r.res.Summary.AgreementFailures) }},
+	{"worst_wait_ms", func(r runRow) string { return formatFloat(r.res.Liveness.Worst.WaitMs) }},
+	{"worst_wait_vehicle", func(r runRow) string { return strconv.Itoa(int(r.res.Liveness.Worst.Vehicle)) }},
+	{"worst_wait_first_round", func(r runRow) string { return strconv.Itoa(r.res.Liveness.Worst.FirstRound) }},
+	{"worst_wait_elections", func(r runRow) string { return strconv.Itoa(r.res.Liveness.Worst.Elections) }},
+	{"starved", func(r runRow) string { return strconv.Itoa(len(r.res.Liveness.Starved)) }},
+}
+
+// Columns of rounds.csv, one row per consensus round.
+var roundColumns = []column[roundRow]{
+	{"run", func(r roundRow) string { return strconv.Itoa(r.run) }},
+	{"hv_ratio", func(r roundRow) string { return formatFloat(r.res.Scenario.HVRatio) }},
+	{"lines", func(r roundRow) string { return strconv.Itoa(r.res.Scenario.Lines) }},
+	{"vision_time_ms", func(r roundRow) string { return strconv.Itoa(r.res.Scenario.VisionTimeMs) }},
+	{"round_size", func(r roundRow) string { return r.res.Scenario.RoundSize }},
+	{"replication", func(r roundRow) string { return strconv.Itoa(r.res.Replication) }},
+	{"seed", func(r roundRow) string { return strconv.FormatInt(r.res.Seed, 10) }},
+	{"round", func(r roundRow) string { return strconv.Itoa(r.round.Round) }},
+	{"participants", func(r roundRow) string { return strconv.Itoa(r.round.Participants) }},
+	{"hvs", func(r roundRow) string { return strconv.Itoa(r.round.HVs) }},
+	{"rpcs", func(r roundRow) string { return strconv.Itoa(r.round.RPCs) }},
+	{"leader", func(r roundRow) string { return strconv.Itoa(int(r.round.Leader)) }},
+	{"time_to_leader_ms", func(r roundRow) string { return formatFloat(r.round.TimeToLeaderMs) }},
+	{"duration_ms", func(r roundRow) string { return formatFloat(r.round.DurationMs) }},
+	{"fallback", func(r roundRow) string { return strconv.FormatBool(r.round.Fallback) }},
+	{"random_tie_breaks", func(r roundRow) string { return strconv.Itoa(r.round.RandomTieBreaks) }},
+	{"ties", func(r roundRow) string { return strconv.Itoa(r.round.Ties) }},
+	{"faster_clock_wins", func(r roundRow) string { return strconv.Itoa(r.round.FasterClockWins) }},
+	{"terms", func(r roundRow) string { return strconv.Itoa(r.round.Terms) }},
+	{"stale_rejected", func(r roundRow) string { return strconv.Itoa(r.round.StaleRejected) }},
+	{"dropped", func(r roundRow) string { return strconv.Itoa(r.round.Dropped) }},
+	{"duplicated", func(r roundRow) string { return strconv.Itoa(r.round.Duplicated) }},
+	{"reordered", func(r roundRow) string { return strconv.Itoa(r.round.Reordered) }},
+	{"partition", func(r roundRow) string { return joinGroups(r.round.Partition) }},
+	{"partitioned", func(r roundRow) string { return strconv.Itoa(r.round.Partitioned) }},
+	{"crashes", func(r roundRow) string { return strconv.Itoa(r.round.Crashes) }},
+	{"restarts", func(r roundRow) string { return strconv.Itoa(r.round.Restarts) }},
+	{"double_votes", func(r roundRow) string { return strconv.Itoa(r.round.DoubleVotes) }},
+	{"invalid_certs", func(r roundRow) string { return strconv.Itoa(r.round.InvalidCerts) }},
+	{"spoofed", func(r roundRow) string { return strconv.Itoa(r.round.Spoofed) }},
+	{"committed", func(r roundRow) string { return joinGroups(r.round.Committed) }},
+	{"commit_acks", func(r roundRow) string { return strconv.Itoa(r.round.CommitAcks) }},
+	{"safety_violations", func(r roundRow) string { return strconv.Itoa(r.round.SafetyViolations) }},
+	{"agreement", func(r roundRow) string { return r.round.Agreement }},
+	{"released", func(r roundRow) string { return joinNumbers(r.round.Released) }},
+	{"fallback_groups", func(r roundRow) string { return joinGroups(r.round.FallbackGroups) }},
+}
+
+// Columns of vehicles.csv, one row per vehicle.
+var vehicleColumns = []column[vehicleRow]{
+	{"run", func(r vehicleRow) string { return strconv.Itoa(r.run) }},
+	{"seed", func(r vehicleRow) string { return strconv.FormatInt(r.res.Seed, 10) }},
+	{"vehicle", func(r vehicleRow) string { return strconv.Itoa(int(r.vehicle.Number)) }},
+	{"hv", func(r vehicleRow) string { return strconv.FormatBool(r.vehicle.HV) }},
+	{"first_round", func(r vehicleRow) string { return strconv.Itoa(r.vehicle.FirstRound) }},
+	{"pass_round", func(r vehicleRow) string { return strconv.Itoa(r.vehicle.PassRound) }},
+	{"elections", func(r vehicleRow) string { return strconv.Itoa(r.vehicle.Elections) }},
+	{"arrival_ms", func(r vehicleRow) string { return formatFloat(r.vehicle.ArrivalMs) }},
+	{"pass_ms", func(r vehicleRow) string { return formatFloat(r.vehicle.PassMs) }},
+	{"delay_ms", func(r vehicleRow) string { return formatFloat(r.vehicle.DelayMs) }},
+	{"via_fallback", func(r vehicleRow) string { return strconv.FormatBool(r.vehicle.ViaFallback) }},
+	{"strategy", func(r vehicleRow) string { return r.res.Adversaries[r.vehicle.Number] }},
+	{"skew_ms", func(r vehicleRow) string { return formatFloat(r.vehicle.SkewMs) }},
+	{"ties_won", func(r vehicleRow) string { return strconv.Itoa(r.vehicle.TiesWon) }},
+	{"ties_lost", func(r vehicleRow) string { return strconv.Itoa(r.vehicle.TiesLost) }},
+}
+
+// Function name: runSweep
+// Runs the base scenario for every combination of the given parameter ranges and replications,
+// and writes one row per run and per round to the output directory.
+func runSweep(args []string) {
+	fs := flag.NewFlagSet("sweep", flag.ExitOnError)
+	scenarioPath := fs.String("scenario", "", "base scenario file (built-in default scenario if empty)")
+	hvRatios := fs.String("hv", "", "comma-separated HV ratios, e.g. 0,0.1,0.2")
+	lines := fs.String("lines", "", "comma-separated lane counts, e.g. 2,4")
+	visionTimes := fs.String("vision", "", "comma-separated T_vision values in ms, e.g. 300,500")
+	roundSizes := fs.String("round-size", "", "comma-separated round size modes (random, fixed)")
+	protocols := fs.String("protocol", "", "comma-separated protocols (leader, schedule)")
+	consensuses := fs.String("consensus", "", "comma-separated consensus modes (crash, bft)")
+	drops := fs.String("drop", "", "comma-separated default message drop probabilities, e.g. 0,0.05,0.1")
+	strategies := fs.String("strategy", "", "comma-separated strategies of the adversaries drawn by ratio (equivocate, vote_all, false_direction, inflate_votes, replay_time)")
+	tieBreaks := fs.String("tie-break", "", "comma-separated tie-break rules (time, random, lamport, hlc, id)")
+	skews := fs.String("skew", "", "comma-separated bounds of the per-vehicle clock skew in ms, e.g. 0,50,200")
+	quorums := fs.String("quorum", "", "comma-separated quorum policies (majority, unanimity, byzantine, responsive, weighted)")
+	replications := fs.Int("reps", 1, "replications per parameter combination")
+	seed := fs.Int64("seed", 0, "seed of the sweep; every run gets its own seed derived from it")
+	outDir := fs.String("out", "results", "output directory")
+	format := fs.String("format", "csv", "output format (csv, json)")
+	fs.Parse(args)
+
+	if *format != "csv" && *format != "json" {
+		log.Fatalf("unknown format %q (csv, json)", *format)
+	}
+	if *replications <= 0 {
+		log.Fatalf("reps must be positive, got %d", *replications)
+	}
+
+	base := config.DefaultScenario()
+	if *scenarioPath != "" {
+		loaded, err := config.LoadScenario(*scenarioPath)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		base = loaded
+	}
+
+	axes := []axis{
+		newAxis("hv", *hvRatios, parseFloat, func(sc *config.Scenario) *float64 { return &sc.HVRatio }, nil),
+		newAxis("lines", *lines, strconv.Atoi, func(sc *config.Scenario) *int { return &sc.Lines }, nil),
+		newAxis("vision", *visionTimes, strconv.Atoi, func(sc *config.Scenario) *int { return &sc.VisionTimeMs }, nil),
+		newAxis("round-size", *roundSizes, parseString, func(sc *config.Scenario) *string { return &sc.RoundSize }, nil),
+		newAxis("protocol", *protocols, parseString, func(sc *config.Scenario) *string { return &sc.Protocol }, nil),
+		newAxis("consensus", *consensuses, parseString, func(sc *config.Scenario) *string { return &sc.Consensus }, nil),
+		newAxis("quorum", *quorums, parseString, func(sc *config.Scenario) *string { return &sc.Quorum }, underBft),
+		newAxis("drop", *drops, parseFloat, func(sc *config.Scenario) *float64 { return &sc.Network.Drop }, nil),
+		newAxis("strategy", *strategies, parseString, func(sc *config.Scenario) *string { return &sc.Adversaries.Strategy }, withoutRatio),
+		newAxis("tie-break", *tieBreaks, parseString, func(sc *config.Scenario) *string { return &sc.TieBreak }, underBft),
+		newAxis("skew", *skews, strconv.Atoi, func(sc *config.Scenario) *int { return &sc.ClockSkew.MaxMs }, nil),
+	}
+	for _, a := range axes {
+		if a.err != nil {
+			log.Fatalf("-%s: %v", a.flag, a.err)
+		}
+	}
+
+	// Build and validate every scenario before the first run starts.
+	scenarios, warnings, err := sweepScenarios(*base, axes)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	for _, warning := range warnings {
+		log.Printf("warning: %s", warning)
+	}
+
+	sweepSeed := *seed
+	if sweepSeed == 0 {
+		sweepSeed = base.Seed
+	}
+	if sweepSeed == 0 {
+		sweepSeed = time.Now().UnixNano()
+	}
+	seeds := utills.NewStream(sweepSeed, "sweep")
+
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		log.Fatalf("create output directory: %v", err)
+	}
+	if err := writeJSON(filepath.Join(*outDir, "scenario.json"), base); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	var results []*RunResult
+	total := len(scenarios) * *replications
+	for _, sc := range scenarios {
+		for rep := 1; rep <= *replications; rep++ {
+			sc.Seed = seeds.Int63()
+			res := runSimulation(&sc)
+			res.Replication = rep
+			results = append(results, res)
+
+			fmt.Printf("[%d/%d] hv=%v lines=%d vision=%dms round_size=%s protocol=%s consensus=%s quorum=%s drop=%v tie_break=%s skew=%dms rep=%d seed=%d: rounds=%d fallback=%.1f%% duration=%.0fms\n",
+				len(results), total, sc.HVRatio, sc.Lines, sc.VisionTimeMs, sc.RoundSize, sc.Protocol, sc.Consensus, sc.Quorum, sc.Network.Drop, sc.TieBreak, sc.ClockSkew.MaxMs, rep, res.Seed,
+				res.Rounds, res.FallbackPct, res.DurationMs)
+		}
+	}
+
+	if *format == "json" {
+		err = writeJSON(filepath.Join(*outDir, "results.json"), results)
+	} else {
+		err = writeRunsCSV(filepath.Join(*outDir, "runs.csv"), results)
+		if err == nil {
+			err = writeRoundsCSV(filepath.Join(*outDir, "rounds.csv"), results)
+		}
+		if err == nil {
+			err = writeVehiclesCSV(filepath.Join(*outDir, "vehicles.csv"), results)
+		}
+	}
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	// The longest wait of the whole sweep, with the parameters and seed that reproduce it.
+	var worst *RunResult
+	for _, res := range results {
+		if worst == nil || res.Liveness.Worst.WaitMs > worst.Liveness.Worst.WaitMs {
+			worst = res
+		}
+	}
+	if worst != nil {
+		fmt.Printf("hv=%v lines=%d vision=%dms round_size=%s protocol=%s consensus=%s: ",
+			worst.Scenario.HVRatio, worst.Scenario.Lines, worst.Scenario.VisionTimeMs, worst.Scenario.RoundSize, worst.Scenario.Protocol, worst.Scenario.Consensus)
+		printWait("worst-case wait of the sweep", worst.Liveness.Worst, worst.Seed)
+	}
+	fmt.Printf("Sweep seed: %d, results written to %s\n", sweepSeed, *outDir)
+}
+
+// Function name: newAxis
+// Builds the axis of a flag from its comma-separated values; an empty list keeps the value of the base scenario.
+// field returns the scenario field the axis sets, inert may be nil for an axis that always has an effect.
+func newAxis[T any](flag string, list string, parse func(string) (T, error), field func(sc *config.Scenario) *T, inert func(sc *config.Scenario) string) axis {
+	a := axis{flag: flag, count: 1, given: list != "", inert: inert}
+	if !a.given {
+		a.set = func(sc *config.Scenario, i int) {}
+		return a
+	}
+	var values []T
+	for _, f := range strings.Split(list, ",") {
+		v, err := parse(strings.TrimSpace(f))
+		if err != nil {
+			a.err = err
+			return a
+		}
+		values = append(values, v)
+	}
+	a.count = len(values)
+	a.set = func(sc *config.Scenario, i int) { *field(sc) = values[i] }
+	return a
+}
+
+// Function name: underBft
+// The quorum policy and the tie-break rule only apply to the crash election.
+func underBft(sc *config.Scenario) string {
+	if sc.Consensus == "bft" {
+		return "consensus is bft"
+	}
+	return ""
+}
+
+// Function name: withoutRatio
+// The strategy only applies to the adversaries drawn by ratio.
+func withoutRatio(sc *config.Scenario) string {
+	if sc.Adversaries.Ratio == 0 {
+		return "adversaries.ratio is 0"
+	}
+	return ""
+}
+
+// Function name: sweepScenarios
+// Builds the Cartesian product of the axes over the base scenario, the first axis changing slowest, and validates
+// every point. A point where a set axis has no effect is only kept for the first value of the axis, since the
+// other values would repeat its runs, and a warning names the axis. An axis that has no effect on any point
+// is an error.
+func sweepScenarios(base config.Scenario, axes []axis) ([]config.Scenario, []string, error) {
+	var scenarios []config.Scenario
+	reasons := make([]string, len(axes))
+	effective := make([]bool, len(axes))
+	index := make([]int, len(axes))
+	for {
+		sc := base
+		for a, ax := range axes {
+			ax.set(&sc, index[a])
+		}
+		keep := true
+		for a, ax := range axes {
+			if !ax.given || ax.inert == nil {
+				continue
+			}
+			if reason := ax.inert(&sc); reason != "" {
+				reasons[a] = reason
+				keep = keep && index[a] == 0
+			} else {
+				effective[a] = true
+			}
+		}
+		if keep {
+			if err := sc.Validate(); err != nil {
+				return nil, nil, fmt.Errorf("invalid sweep point: %v", err)
+			}
+			scenarios = append(scenarios, sc)
+		}
+
+		// advance the indexes like an odometer, the last axis fastest
+		a := len(axes) - 1
+		for ; a >= 0; a-- {
+			index[a]++
+			if index[a] < axes[a].count {
+				break
+			}
+			index[a] = 0
+		}
+		if a < 0 {
+			break
+		}
+	}
+
+	var warnings []string
+	for a, ax := range axes {
+		if reasons[a] == "" {
+			continue
+		}
+		if !effective[a] {
+			return nil, nil, fmt.Errorf("-%s has no effect: %s", ax.flag, reasons[a])
+		}
+		warnings = append(warnings, fmt.Sprintf("-%s has no effect where %s; those points only run with its first value", ax.flag, reasons[a]))
+	}
+	return scenarios, warnings, nil
+}
+
+// Function name: writeRunsCSV
+// Writes one row per run.
+func writeRunsCSV(path string, results []*RunResult) error {
+	var rows [][]string
+	for run, res := range results {
+		rows = append(rows, csvRow(runColumns, runRow{run + 1, res}))
+	}
+	return writeCSV(path, csvHeader(runColumns), rows)
+}
+
+// Function name: writeRoundsCSV
+// Writes one row per consensus round of every run.
+func writeRoundsCSV(path string, results []*RunResult) error {
+	var rows [][]string
+	for run, res := range results {
+		for _, round := range res.RoundResults {
+			rows = append(rows, csvRow(roundColumns, roundRow{run + 1, res, round}))
+		}
+	}
+	return writeCSV(path, csvHeader(roundColumns), rows)
+}
+
+// Function name: writeVehiclesCSV
+// Writes one row per vehicle of every run.
+func writeVehiclesCSV(path string, results []*RunResult) error {
+	var rows [][]string
+	for run, res := range results {
+		for _, vehicle := range res.Vehicles {
+			rows = append(rows, csvRow(vehicleColumns, vehicleRow{run + 1, res, vehicle}))
+		}
+	}
+	return writeCSV(path, csvHeader(vehicleColumns), rows)
+}
+
+// Function name: csvHeader
+// Returns the column names of a CSV file.
+func csvHeader[T any](columns []column[T]) []string {
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.name
+	}
+	return header
+}
+
+// Function name: csvRow
+// Formats one row of a CSV file.
+func csvRow[T any](columns []column[T], row T) []string {
+	record := make([]string, len(columns))
+	for i, c := range columns {
+		record[i] = c.value(row)
+	}
+	return record
+}
+
+// Function name: writeCSV
+// Writes a header and rows to a CSV file.
+func writeCSV(path string, header []string, rows [][]string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create %s: %v", path, err)
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write(header)
+	w.WriteAll(rows)
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("write %s: %v", path, err)
+	}
+	return nil
+}
+
+// Function name: writeJSON
+// Writes a value as indented JSON.
+func writeJSON(path string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write %s: %v", path, err)
+	}
+	return nil
+}
+
+// Function name: joinNumbers
+// Joins vehicle numbers with spaces for a single CSV field.
+func joinNumbers(numbers []int32) string {
+	fields := make([]string, len(numbers))
+	for i, n := range numbers {
+		fields[i] = strconv.Itoa(int(n))
+	}
+	return strings.Join(fields, " ")
+}
+
+// Function name: joinGroups
+// Joins groups of vehicle numbers as "1 2|3" for a single CSV field.
+func joinGroups(groups [][]int32) string {
+	fields := make([]string, len(groups))
+	for i, g := range groups {
+		fields[i] = joinNumbers(g)
+	}
+	return strings.Join(fields, "|")
+}
+
+// Function name: formatFloat
+// Formats a float with the shortest exact representation.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// Function name: parseFloat
+// Parses one float value of a sweep flag.
+func parseFloat(field string) (float64, error) {
+	return strconv.ParseFloat(field, 64)
+}
+
+// Function name: parseString
+// Returns one string value of a sweep flag.
+func parseString(field string) (string, error) {
+	return field, nil
+}
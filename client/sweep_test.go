@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	config "main/config"
+)
+
+func TestSweepScenarios(t *testing.T) {
+	lines := func(list string) axis {
+		return newAxis("lines", list, strconv.Atoi, func(sc *config.Scenario) *int { return &sc.Lines }, nil)
+	}
+	consensus := func(list string) axis {
+		return newAxis("consensus", list, parseString, func(sc *config.Scenario) *string { return &sc.Consensus }, nil)
+	}
+	quorum := func(list string) axis {
+		return newAxis("quorum", list, parseString, func(sc *config.Scenario) *string { return &sc.Quorum }, underBft)
+	}
+	strategy := func(list string) axis {
+		return newAxis("strategy", list, parseString, func(sc *config.Scenario) *string { return &sc.Adversaries.Strategy }, withoutRatio)
+	}
+
+	tests := []struct {
+		name     string
+		axes     []axis
+		want     []string // lines/consensus/quorum of every point, in order
+		warnings int
+		err      bool
+	}{
+		{"base scenario only", []axis{lines(""), consensus(""), quorum("")}, []string{"2/crash/majority"}, 0, false},
+		{"first axis changes slowest", []axis{lines("2,4"), consensus(""), quorum("majority,unanimity")},
+			[]string{"2/crash/majority", "2/crash/unanimity", "4/crash/majority", "4/crash/unanimity"}, 0, false},
+		{"quorum inert under bft runs once", []axis{lines(""), consensus("crash,bft"), quorum("majority,unanimity")},
+			[]string{"2/crash/majority", "2/crash/unanimity", "2/bft/majority"}, 1, false},
+		{"quorum inert everywhere", []axis{lines(""), consensus("bft"), quorum("majority,unanimity")}, nil, 0, true},
+		{"strategy without ratio", []axis{lines(""), consensus(""), strategy("vote_all,equivocate")}, nil, 0, true},
+		{"invalid point", []axis{lines("0"), consensus(""), quorum("")}, nil, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := *config.DefaultScenario()
+			base.Lines, base.Consensus, base.Quorum = 2, "crash", "majority"
+			scenarios, warnings, err := sweepScenarios(base, tt.axes)
+			if (err != nil) != tt.err {
+				t.Fatalf("err = %v, want error %v", err, tt.err)
+			}
+			if len(warnings) != tt.warnings {
+				t.Fatalf("warnings = %q, want %d", warnings, tt.warnings)
+			}
+			var got []string
+			for _, sc := range scenarios {
+				got = append(got, fmt.Sprintf("%d/%s/%s", sc.Lines, sc.Consensus, sc.Quorum))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Fatalf("points = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAxisParseError(t *testing.T) {
+	a := newAxis("lines", "2,x", strconv.Atoi, func(sc *config.Scenario) *int { return &sc.Lines }, nil)
+	if a.err == nil {
+		t.Fatal("newAxis accepted a value that is not a number")
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	direction "main/config/directionBoolean"
+)
+
+// Hangul syllables used on Korean private-car plates.
+var plateSyllables = []string{
+	"가", "나", "다", "라", "마", "거", "너", "더", "러", "머", "버", "서", "어", "저",
+	"고", "노", "도", "로", "모", "보", "소", "오", "조", "구", "누", "두", "루", "무", "부", "수", "우", "주",
+}
+
+// Function name: assignLicensePlates
+// Gives every vehicle a unique plate in the current Korean format, e.g. "123가4567".
+func assignLicensePlates(r *rand.Rand, vehicles []int) map[int32]string {
+	plates := make(map[int32]string)
+	used := make(map[string]bool)
+	for _, v := range vehicles {
+		for {
+			plate := fmt.Sprintf("%03d%s%04d", r.Intn(1000), plateSyllables[r.Intn(len(plateSyllables))], r.Intn(10000))
+			if !used[plate] {
+				used[plate] = true
+				plates[int32(v)] = plate
+				break
+			}
+		}
+	}
+	return plates
+}
+
+// Function name: visionPassingOrder
+// Applies the vision-based priority rule: vehicles pass in lexicographic order of their license plates,
+// and vehicles with the same plate (e.g. an unread one) in order of their numbers. With "sequential" release every vehicle crosses alone; with "grouped" release consecutive vehicles
+// in plate order cross together as long as their movements are pairwise compatible.
+func visionPassingOrder(vehicles []int32, plates map[int32]string, directions map[int32]string, release string) [][]int32 {
+	order := append([]int32(nil), vehicles...)
+	sort.Slice(order, func(i, j int) bool {
+		if plates[order[i]] != plates[order[j]] {
+			return plates[order[i]] < plates[order[j]]
+		}
+		return order[i] < order[j]
+	})
+
+	var groups [][]int32
+	for _, v := range order {
+		if release == "grouped" && len(groups) > 0 {
+			last := groups[len(groups)-1]
+			compatible := true
+			for _, w := range last {
+				if !direction.Compatible(directions[v], directions[w]) {
+					compatible = false
+					break
+				}
+			}
+			if compatible {
+				groups[len(groups)-1] = append(last, v)
+				continue
+			}
+		}
+		groups = append(groups, []int32{v})
+	}
+	return groups
+}
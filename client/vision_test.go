@@ -0,0 +1,60 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestVisionPassingOrder(t *testing.T) {
+	plates := map[int32]string{1: "123나4567", 2: "123가4567", 3: "099다0001", 4: "123가4568", 5: "", 6: ""}
+	// Rs and Ls may cross together, Rl conflicts with both
+	directions := map[int32]string{1: "Rs", 2: "Ls", 3: "Rl", 4: "Rs", 5: "Rs", 6: "Rl"}
+
+	tests := []struct {
+		name     string
+		vehicles []int32
+		release  string
+		want     [][]int32
+	}{
+		{"digits before syllable", []int32{1, 3}, "sequential", [][]int32{{3}, {1}}},
+		{"syllable order", []int32{1, 2}, "sequential", [][]int32{{2}, {1}}},
+		{"last digits", []int32{4, 2}, "sequential", [][]int32{{2}, {4}}},
+		{"same plate by vehicle number", []int32{6, 5}, "sequential", [][]int32{{5}, {6}}},
+		{"grouped compatible neighbours", []int32{1, 2, 4}, "grouped", [][]int32{{2, 4, 1}}},
+		{"grouped conflict starts a group", []int32{1, 2, 3}, "grouped", [][]int32{{3}, {2, 1}}},
+		{"grouped same plate", []int32{6, 5, 2}, "grouped", [][]int32{{5}, {6}, {2}}},
+		{"no vehicles", nil, "grouped", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := visionPassingOrder(tt.vehicles, plates, directions, tt.release); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssignLicensePlates(t *testing.T) {
+	vehicles := make([]int, 200)
+	for i := range vehicles {
+		vehicles[i] = i + 1
+	}
+	plates := assignLicensePlates(rand.New(rand.NewSource(7)), vehicles)
+	format := regexp.MustCompile(`^[0-9]{3}\p{Hangul}[0-9]{4}$`)
+	used := make(map[string]bool)
+	for _, v := range vehicles {
+		plate := plates[int32(v)]
+		if !format.MatchString(plate) {
+			t.Fatalf("plate %q of vehicle %d is not in the Korean format", plate, v)
+		}
+		if used[plate] {
+			t.Fatalf("plate %q is given twice", plate)
+		}
+		used[plate] = true
+	}
+	if again := assignLicensePlates(rand.New(rand.NewSource(7)), vehicles); !reflect.DeepEqual(again, plates) {
+		t.Fatal("the same seed gave different plates")
+	}
+}
@@ -0,0 +1,285 @@
+package clock
+
+import (
+	"container/heap"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Clock is the time source shared by the client and the vehicle servers.
+// Every wait and every timestamp of the simulation goes through it, so a run can use either
+// the wall clock or a virtual clock without changing its timing rules.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	SleepUntil(t time.Time)
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a wait on a Clock that can be given up before it fires.
+type Timer interface {
+	C() <-chan struct{} // closed when the timer fires
+	Stop() bool         // false if the timer has already fired
+}
+
+// Tracker is implemented by clocks that must not advance while an RPC is in flight.
+// The transport reports every call to it, so time only moves once all delivered messages were handled.
+type Tracker interface {
+	BeginCall()
+	EndCall()
+}
+
+// Real is the wall clock.
+type Real struct{}
+
+// Function name: Now
+// Returns the current wall-clock time.
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// Function name: Sleep
+// Blocks for d of real time.
+func (Real) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// Function name: SleepUntil
+// Blocks until the wall clock reaches t.
+func (Real) SleepUntil(t time.Time) {
+	time.Sleep(time.Until(t))
+}
+
+// Function name: NewTimer
+// Starts a wall-clock timer that fires after d.
+func (Real) NewTimer(d time.Duration) Timer {
+	t := &realTimer{fired: make(chan struct{})}
+	t.timer = time.AfterFunc(d, func() { close(t.fired) })
+	return t
+}
+
+// realTimer is a Timer on the wall clock.
+type realTimer struct {
+	timer *time.Timer
+	fired chan struct{}
+}
+
+func (t *realTimer) C() <-chan struct{} { return t.fired }
+func (t *realTimer) Stop() bool         { return t.timer.Stop() }
+
+// Virtual is a discrete-event clock.
+// Time only moves when somebody sleeps, and modelled delays (crossing times, T_vision, jitter)
+// cost no wall-clock time. Sleepers are woken strictly in order of their wake-up instant,
+// so concurrent delays keep the ordering they would have on the wall clock:
+// the clock waits until no RPC is in flight, every woken sleeper has resumed and no new sleeper arrived
+// for a short settle period,
+// jumps to the earliest pending instant and wakes every sleeper due at that instant.
+type Virtual struct {
+	mu          sync.Mutex
+	now         time.Time
+	waiters     waiterHeap
+	seq         int
+	lastChange  time.Time // wall-clock time of the last new sleeper, wake-up or finished call
+	changes     int       // number of new sleepers, wake-ups and finished calls so far
+	calls       int       // RPCs in flight and woken sleepers that have not resumed yet
+	dispatching bool
+}
+
+// settle is the wall-clock quiet period before the next instant is dispatched.
+// It lets woken goroutines reach their next RPC or sleep; calls in flight are tracked separately.
+const settle = 2 * time.Millisecond
+
+// yields is how often dispatch lets runnable goroutines go first before it advances the clock. The settle
+// period is wall-clock time, which also passes while the process is descheduled on a loaded machine.
+const yields = 4
+
+// waiter is one blocked sleeper or pending timer.
+type waiter struct {
+	at    time.Time
+	seq   int
+	wake  chan struct{}
+	index int  // position in the heap, -1 once woken or stopped
+	sleep bool // a blocked Sleep or SleepUntil, which holds the clock until its goroutine resumes
+}
+
+// waiterHeap orders sleepers by wake-up instant, then by arrival.
+type waiterHeap []*waiter
+
+func (h waiterHeap) Len() int { return len(h) }
+func (h waiterHeap) Less(i, j int) bool {
+	if h[i].at.Equal(h[j].at) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].at.Before(h[j].at)
+}
+func (h waiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *waiterHeap) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+func (h *waiterHeap) Pop() any {
+	old := *h
+	w := old[len(old)-1]
+	w.index = -1
+	*h = old[:len(old)-1]
+	return w
+}
+
+// Function name: NewVirtual
+// Creates a virtual clock that starts at the given instant.
+func NewVirtual(start time.Time) *Virtual {
+	return &Virtual{now: start}
+}
+
+// Function name: Now
+// Returns the current virtual time.
+func (v *Virtual) Now() time.Time {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.now
+}
+
+// Function name: Sleep
+// Blocks until the virtual time has advanced by d.
+func (v *Virtual) Sleep(d time.Duration) {
+	v.SleepUntil(v.Now().Add(d))
+}
+
+// Function name: SleepUntil
+// Blocks until the virtual time reaches t; earlier sleepers are woken first.
+func (v *Virtual) SleepUntil(t time.Time) {
+	w := v.wait(t, true)
+	<-w.wake
+	if w.sleep {
+		// dispatch counted this sleeper as busy when it woke it; the settle period starts once it runs again
+		v.EndCall()
+	}
+}
+
+// Function name: NewTimer
+// Starts a timer that fires once the virtual time has advanced by d.
+func (v *Virtual) NewTimer(d time.Duration) Timer {
+	return &virtualTimer{clock: v, waiter: v.wait(v.Now().Add(d), false)}
+}
+
+// Function name: wait
+// Registers a waiter for instant t; a waiter for an instant that has passed is woken at once.
+// The clock does not advance past a woken sleeper until it resumes; a timer's channel may never be read.
+func (v *Virtual) wait(t time.Time, sleep bool) *waiter {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	w := &waiter{at: t, seq: v.seq, wake: make(chan struct{}), index: -1, sleep: sleep}
+	if !t.After(v.now) {
+		w.sleep = false
+		close(w.wake)
+		return w
+	}
+	v.seq++
+	heap.Push(&v.waiters, w)
+	v.touch()
+	if !v.dispatching {
+		v.dispatching = true
+		go v.dispatch()
+	}
+	return w
+}
+
+// virtualTimer is a Timer on the virtual clock; a stopped timer no longer holds the clock back.
+type virtualTimer struct {
+	clock  *Virtual
+	waiter *waiter
+}
+
+func (t *virtualTimer) C() <-chan struct{} { return t.waiter.wake }
+func (t *virtualTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	if t.waiter.index < 0 {
+		return false
+	}
+	heap.Remove(&t.clock.waiters, t.waiter.index)
+	return true
+}
+
+// Function name: BeginCall
+// Marks an RPC as in flight; the clock does not advance until it ends.
+func (v *Virtual) BeginCall() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.calls++
+}
+
+// Function name: EndCall
+// Marks an RPC as handled.
+func (v *Virtual) EndCall() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.calls--
+	v.touch()
+}
+
+// Function name: touch
+// Records a change of the clock state, which restarts the settle period; the caller holds mu.
+func (v *Virtual) touch() {
+	v.lastChange = time.Now()
+	v.changes++
+}
+
+// Function name: dispatch
+// Advances the clock from one pending instant to the next until no sleeper is left.
+func (v *Virtual) dispatch() {
+	for {
+		time.Sleep(settle)
+
+		v.mu.Lock()
+		changes := v.changes
+		v.mu.Unlock()
+		for i := 0; i < yields; i++ {
+			runtime.Gosched()
+		}
+
+		v.mu.Lock()
+		if v.calls > 0 || v.changes != changes || time.Since(v.lastChange) < settle {
+			v.mu.Unlock()
+			continue
+		}
+		if v.waiters.Len() == 0 {
+			v.dispatching = false
+			v.mu.Unlock()
+			return
+		}
+
+		next := v.waiters[0].at
+		if next.After(v.now) {
+			v.now = next
+		}
+		for v.waiters.Len() > 0 && !v.waiters[0].at.After(next) {
+			w := heap.Pop(&v.waiters).(*waiter)
+			if w.sleep {
+				v.calls++
+			}
+			close(w.wake)
+		}
+		v.touch()
+		v.mu.Unlock()
+	}
+}
+
+// Start of every virtual run, fixed so that two runs of a seed also stamp the same ElectionTime.
+var Epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Function name: New
+// Returns the clock selected by name: "real" (default) or "virtual", which starts at Epoch.
+func New(name string) Clock {
+	if name == "virtual" {
+		return NewVirtual(Epoch)
+	}
+	return Real{}
+}
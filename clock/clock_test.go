@@ -0,0 +1,110 @@
+package clock
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestVirtualWakesInOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		delays []time.Duration
+		want   []time.Duration
+	}{
+		{"ascending", []time.Duration{10, 20, 30}, []time.Duration{10, 20, 30}},
+		{"descending", []time.Duration{30, 20, 10}, []time.Duration{10, 20, 30}},
+		{"past instant", []time.Duration{0, 5}, []time.Duration{0, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVirtual(epoch)
+			var mu sync.Mutex
+			var woken []time.Duration
+			var wg sync.WaitGroup
+			for _, d := range tt.delays {
+				wg.Add(1)
+				go func(d time.Duration) {
+					defer wg.Done()
+					v.SleepUntil(epoch.Add(d * time.Millisecond))
+					mu.Lock()
+					woken = append(woken, v.Now().Sub(epoch)/time.Millisecond)
+					mu.Unlock()
+				}(d)
+			}
+			wg.Wait()
+
+			if len(woken) != len(tt.want) {
+				t.Fatalf("woken %v, want %v", woken, tt.want)
+			}
+			for i := range woken {
+				if woken[i] != tt.want[i] {
+					t.Fatalf("woken %v, want %v", woken, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestVirtualSleepAdvancesNow(t *testing.T) {
+	v := NewVirtual(epoch)
+	v.Sleep(250 * time.Millisecond)
+	v.Sleep(750 * time.Millisecond)
+	if got := v.Now().Sub(epoch); got != time.Second {
+		t.Fatalf("Now after sleeps = %v, want 1s", got)
+	}
+}
+
+func TestVirtualWaitsForCalls(t *testing.T) {
+	v := NewVirtual(epoch)
+	v.BeginCall()
+
+	woke := make(chan struct{})
+	go func() {
+		v.Sleep(time.Millisecond)
+		close(woke)
+	}()
+
+	select {
+	case <-woke:
+		t.Fatal("clock advanced while a call was in flight")
+	case <-time.After(20 * settle):
+	}
+
+	v.EndCall()
+	select {
+	case <-woke:
+	case <-time.After(time.Second):
+		t.Fatal("clock did not advance after the call ended")
+	}
+}
+
+func TestVirtualTimer(t *testing.T) {
+	v := NewVirtual(epoch)
+
+	stopped := v.NewTimer(10 * time.Millisecond)
+	fired := v.NewTimer(5 * time.Millisecond)
+	if !stopped.Stop() {
+		t.Fatal("Stop of a pending timer returned false")
+	}
+
+	v.Sleep(20 * time.Millisecond)
+	if got := v.Now().Sub(epoch); got != 20*time.Millisecond {
+		t.Fatalf("Now = %v, want 20ms", got)
+	}
+	select {
+	case <-fired.C():
+	default:
+		t.Fatal("timer did not fire")
+	}
+	if fired.Stop() {
+		t.Fatal("Stop of a fired timer returned true")
+	}
+	select {
+	case <-stopped.C():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+}
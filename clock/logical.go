@@ -0,0 +1,73 @@
+package clock
+
+import "sync"
+
+// Lamport is a Lamport logical clock: it counts events and jumps past every reading it receives,
+// so a stamp is always later than the stamps that causally precede it.
+type Lamport struct {
+	mu   sync.Mutex
+	time int64
+}
+
+// Function name: Tick
+// Advances the clock for a local or send event and returns the new reading.
+func (l *Lamport) Tick() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.time++
+	return l.time
+}
+
+// Function name: Observe
+// Advances the clock past a received reading.
+func (l *Lamport) Observe(remote int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.time = max(l.time, remote) + 1
+}
+
+// HLC is a hybrid logical clock: its wall component follows the highest physical time seen, and the
+// logical counter orders events within the same wall time, so stamps stay close to physical time
+// but never go backwards when local clocks are skewed.
+type HLC struct {
+	mu      sync.Mutex
+	wall    int64
+	logical int32
+}
+
+// Function name: Tick
+// Advances the clock for a local or send event at the given physical time and returns the new reading.
+func (h *HLC) Tick(physical int64) (int64, int32) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if physical > h.wall {
+		h.wall = physical
+		h.logical = 0
+	} else {
+		h.logical++
+	}
+	return h.wall, h.logical
+}
+
+// Function name: Observe
+// Advances the clock past a received reading at the given physical time.
+func (h *HLC) Observe(physical int64, wall int64, logical int32) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch {
+	case physical > h.wall && physical > wall:
+		h.wall = physical
+		h.logical = 0
+	case wall > h.wall:
+		h.wall = wall
+		h.logical = logical + 1
+	case h.wall > wall:
+		h.logical++
+	default:
+		h.logical = max(h.logical, logical) + 1
+	}
+}
@@ -0,0 +1,63 @@
+package clock
+
+import "testing"
+
+func TestLamport(t *testing.T) {
+	tests := []struct {
+		name     string
+		observed []int64 // readings received before the next tick; 0 is a local tick
+		want     int64
+	}{
+		{"local ticks", []int64{0, 0, 0}, 4},
+		{"jumps past a later reading", []int64{10}, 12},
+		{"ignores an earlier reading", []int64{0, 0, 1}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var l Lamport
+			for _, remote := range tt.observed {
+				if remote == 0 {
+					l.Tick()
+				} else {
+					l.Observe(remote)
+				}
+			}
+			if got := l.Tick(); got != tt.want {
+				t.Fatalf("Tick = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHLC(t *testing.T) {
+	type reading struct {
+		wall    int64
+		logical int32
+	}
+	tests := []struct {
+		name     string
+		physical int64
+		observe  *reading // received before the tick, if any
+		start    reading
+		want     reading
+	}{
+		{"physical time moves on", 20, nil, reading{10, 3}, reading{20, 0}},
+		{"physical time stands still", 10, nil, reading{10, 3}, reading{10, 4}},
+		{"remote wall ahead", 5, &reading{30, 2}, reading{10, 0}, reading{30, 4}},
+		{"remote wall behind", 5, &reading{8, 7}, reading{10, 1}, reading{10, 3}},
+		{"same wall", 5, &reading{10, 7}, reading{10, 1}, reading{10, 9}},
+		{"physical ahead of both", 50, &reading{30, 2}, reading{10, 0}, reading{50, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := HLC{wall: tt.start.wall, logical: tt.start.logical}
+			if tt.observe != nil {
+				h.Observe(tt.physical, tt.observe.wall, tt.observe.logical)
+			}
+			wall, logical := h.Tick(tt.physical)
+			if (reading{wall, logical}) != tt.want {
+				t.Fatalf("Tick = (%d, %d), want (%d, %d)", wall, logical, tt.want.wall, tt.want.logical)
+			}
+		})
+	}
+}
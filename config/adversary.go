@@ -0,0 +1,50 @@
+package config
+
+import "fmt"
+
+// Strategies a malicious vehicle can follow.
+var Strategies = []string{"equivocate", "vote_all", "false_direction", "inflate_votes", "replay_time"}
+
+// Adversaries selects the CAVs that do not follow the protocol and what they do instead.
+type Adversaries struct {
+	Ratio    float64          `json:"ratio"`    // share of all vehicles drawn as malicious CAVs with the default strategy
+	Strategy string           `json:"strategy"` // strategy of the vehicles drawn by ratio
+	Vehicles map[int32]string `json:"vehicles"` // strategy per vehicle number, on top of the drawn ones
+}
+
+// Function name: Enabled
+// Reports whether any vehicle is malicious.
+func (a Adversaries) Enabled() bool {
+	return a.Ratio > 0 || len(a.Vehicles) > 0
+}
+
+// Function name: Validate
+// Checks the ratio and that every strategy is known.
+func (a Adversaries) Validate() error {
+	if a.Ratio < 0 || a.Ratio > 1 {
+		return fmt.Errorf("adversaries.ratio must be within [0, 1], got %v", a.Ratio)
+	}
+	if a.Ratio > 0 && !knownStrategy(a.Strategy) {
+		return fmt.Errorf("unknown adversaries.strategy %q %v", a.Strategy, Strategies)
+	}
+	for number, strategy := range a.Vehicles {
+		if number <= 0 {
+			return fmt.Errorf("adversaries.vehicles: vehicle numbers start at 1, got %d", number)
+		}
+		if !knownStrategy(strategy) {
+			return fmt.Errorf("unknown strategy %q for vehicle %d %v", strategy, number, Strategies)
+		}
+	}
+	return nil
+}
+
+// Function name: knownStrategy
+// Reports whether the strategy is one of Strategies.
+func knownStrategy(strategy string) bool {
+	for _, s := range Strategies {
+		if s == strategy {
+			return true
+		}
+	}
+	return false
+}
@@ -1,3 +1,3 @@
-﻿package config
+package config
 
 const BasePort = 32000
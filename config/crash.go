@@ -0,0 +1,42 @@
+package config
+
+import "fmt"
+
+// Crash describes crash faults of CAV servers during an election.
+type Crash struct {
+	Probability    float64 `json:"probability"`      // chance that a CAV crashes when it reaches the crash point; 0 disables crashes
+	Point          string  `json:"point"`            // candidate / vote / follow / commit / any
+	Restart        string  `json:"restart"`          // none (crash-stop) / with_state / without_state (crash-recovery)
+	RestartAfterMs int     `json:"restart_after_ms"` // downtime before a crashed server comes back
+}
+
+// Function name: Enabled
+// Reports whether crash faults are configured.
+func (c Crash) Enabled() bool {
+	return c.Probability > 0
+}
+
+// Function name: Validate
+// Checks the crash probability, the crash point and the restart mode.
+func (c Crash) Validate() error {
+	if c.Probability < 0 || c.Probability > 1 {
+		return fmt.Errorf("crash.probability must be within [0, 1], got %v", c.Probability)
+	}
+	if !c.Enabled() {
+		return nil
+	}
+	switch c.Point {
+	case "candidate", "vote", "follow", "commit", "any":
+	default:
+		return fmt.Errorf("unknown crash.point %q (candidate, vote, follow, commit, any)", c.Point)
+	}
+	switch c.Restart {
+	case "none", "with_state", "without_state":
+	default:
+		return fmt.Errorf("unknown crash.restart %q (none, with_state, without_state)", c.Restart)
+	}
+	if c.RestartAfterMs < 0 {
+		return fmt.Errorf("crash.restart_after_ms must not be negative, got %d", c.RestartAfterMs)
+	}
+	return nil
+}
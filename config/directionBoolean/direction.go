@@ -1,5 +1,8 @@
 package directionboolean
 
+// Directions lists every movement code: approach (R, L, D, U) followed by s(traight), l(eft) or r(ight).
+var Directions = []string{"Rs", "Rl", "Rr", "Ls", "Ll", "Lr", "Ds", "Dl", "Dr", "Us", "Ul", "Ur"}
+
 // Function name: SliceToMap
 // Converts a slice of strings into a map for O(1) membership lookup.
 func SliceToMap(slice []string) map[string]bool {
@@ -41,3 +44,10 @@ func DirectionBoolean(key string, value string) bool {
 		return false
 	}
 }
+
+// Function name: Compatible
+// Returns true if two movements may cross together, i.e. each one is compatible with the other.
+// The DirectionBoolean table is not symmetric for every pair, so both directions are checked.
+func Compatible(a string, b string) bool {
+	return DirectionBoolean(a, b) && DirectionBoolean(b, a)
+}
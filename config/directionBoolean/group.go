@@ -0,0 +1,134 @@
+package directionboolean
+
+import "sort"
+
+// Function name: ConflictFree
+// Returns true if every pair of vehicles in the group has compatible movements.
+func ConflictFree(group []int32, directions map[int32]string) bool {
+	for i := 0; i < len(group); i++ {
+		for j := i + 1; j < len(group); j++ {
+			if !Compatible(directions[group[i]], directions[group[j]]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Function name: MaximalGroup
+// Returns a largest pairwise compatible group among the vehicles that contains every required vehicle.
+// The groups are the cliques of the compatibility graph, which is searched exhaustively with Bron-Kerbosch;
+// a round holds at most lines*4 vehicles, so the search stays small. Among groups of equal size the one
+// with the lowest vehicle numbers wins, so every node computes the same group.
+// Returns nil if the required vehicles are not compatible with each other.
+func MaximalGroup(vehicles []int32, directions map[int32]string, required ...int32) []int32 {
+	if !ConflictFree(required, directions) {
+		return nil
+	}
+
+	isRequired := make(map[int32]bool)
+	for _, v := range required {
+		isRequired[v] = true
+	}
+
+	// only vehicles compatible with every required vehicle can join
+	var candidates []int32
+	for _, v := range vehicles {
+		if isRequired[v] {
+			continue
+		}
+		compatible := true
+		for _, r := range required {
+			if !Compatible(directions[v], directions[r]) {
+				compatible = false
+				break
+			}
+		}
+		if compatible {
+			candidates = append(candidates, v)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i] < candidates[j] })
+
+	var best []int32
+	var search func(clique, candidates, excluded []int32)
+	search = func(clique, candidates, excluded []int32) {
+		if len(candidates) == 0 {
+			if len(excluded) == 0 && len(clique) > len(best) {
+				best = append([]int32(nil), clique...)
+			}
+			return
+		}
+		if len(clique)+len(candidates) <= len(best) {
+			return
+		}
+		for len(candidates) > 0 {
+			v := candidates[0]
+			search(append(clique, v), neighbours(v, candidates, directions), neighbours(v, excluded, directions))
+			candidates = candidates[1:]
+			excluded = append(excluded, v)
+		}
+	}
+	search(nil, candidates, nil)
+
+	group := append([]int32(nil), required...)
+	return append(group, best...)
+}
+
+// Function name: PartitionGroups
+// Splits the vehicles into passing groups by taking a maximal group of the remaining vehicles again and again.
+// The first group must contain the required vehicles (e.g. the elected leader).
+func PartitionGroups(vehicles []int32, directions map[int32]string, required ...int32) [][]int32 {
+	var groups [][]int32
+	remaining := append([]int32(nil), vehicles...)
+	if len(required) > 0 {
+		first := MaximalGroup(remaining, directions, required...)
+		if first == nil {
+			return nil
+		}
+		groups = append(groups, first)
+		remaining = without(remaining, first)
+	}
+
+	for len(remaining) > 0 {
+		// the lowest remaining vehicle anchors the group, so no vehicle is postponed forever
+		lowest := remaining[0]
+		for _, v := range remaining {
+			if v < lowest {
+				lowest = v
+			}
+		}
+		group := MaximalGroup(remaining, directions, lowest)
+		groups = append(groups, group)
+		remaining = without(remaining, group)
+	}
+	return groups
+}
+
+// Function name: without
+// Returns the vehicles of the list that are not in the group.
+func without(list []int32, group []int32) []int32 {
+	inGroup := make(map[int32]bool)
+	for _, v := range group {
+		inGroup[v] = true
+	}
+	var rest []int32
+	for _, v := range list {
+		if !inGroup[v] {
+			rest = append(rest, v)
+		}
+	}
+	return rest
+}
+
+// Function name: neighbours
+// Returns the vehicles of the list whose movement is compatible with the movement of v.
+func neighbours(v int32, list []int32, directions map[int32]string) []int32 {
+	var result []int32
+	for _, w := range list {
+		if w != v && Compatible(directions[v], directions[w]) {
+			result = append(result, w)
+		}
+	}
+	return result
+}
@@ -0,0 +1,63 @@
+package directionboolean
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Rs, Ls and Ur are pairwise compatible; Rl conflicts with all three and Dr conflicts with Ur.
+var movements = map[int32]string{1: "Rs", 2: "Ls", 3: "Ur", 4: "Rl", 5: "Dr"}
+
+func TestMaximalGroup(t *testing.T) {
+	tests := []struct {
+		name     string
+		vehicles []int32
+		required []int32
+		want     []int32
+	}{
+		{"largest clique", []int32{1, 2, 3, 4}, nil, []int32{1, 2, 3}},
+		{"required leader alone", []int32{1, 2, 3, 4}, []int32{4}, []int32{4}},
+		{"required leader first", []int32{1, 2, 3, 5}, []int32{3}, []int32{3, 1, 2}},
+		{"conflicting required", []int32{1, 4}, []int32{1, 4}, nil},
+		{"equal size picks lowest", []int32{4, 1}, nil, []int32{1}},
+		{"no vehicles", nil, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MaximalGroup(tt.vehicles, movements, tt.required...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("MaximalGroup = %v, want %v", got, tt.want)
+			}
+			if !ConflictFree(got, movements) {
+				t.Fatalf("MaximalGroup = %v is not conflict-free", got)
+			}
+		})
+	}
+}
+
+func TestPartitionGroups(t *testing.T) {
+	tests := []struct {
+		name     string
+		vehicles []int32
+		required []int32
+		want     [][]int32
+	}{
+		{"leader first", []int32{1, 2, 3, 4}, []int32{4}, [][]int32{{4}, {1, 2, 3}}},
+		{"lowest anchors", []int32{4, 3, 2, 1}, nil, [][]int32{{1, 2, 3}, {4}}},
+		{"conflicting anchor", []int32{3, 5}, nil, [][]int32{{3}, {5}}},
+		{"conflicting required", []int32{1, 4}, []int32{1, 4}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PartitionGroups(tt.vehicles, movements, tt.required...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("PartitionGroups = %v, want %v", got, tt.want)
+			}
+			for _, group := range got {
+				if !ConflictFree(group, movements) {
+					t.Fatalf("group %v is not conflict-free", group)
+				}
+			}
+		})
+	}
+}
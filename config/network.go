@@ -0,0 +1,141 @@
+package config
+
+import "fmt"
+
+// Latency is the distribution of the one-way delay of a message in ms.
+type Latency struct {
+	Distribution string  `json:"distribution"` // none / constant / uniform / normal / exponential
+	MeanMs       float64 `json:"mean_ms"`      // constant value, normal and exponential mean
+	StddevMs     float64 `json:"stddev_ms"`    // normal standard deviation
+	MinMs        float64 `json:"min_ms"`       // uniform lower bound; lower samples of the other distributions are raised to it
+	MaxMs        float64 `json:"max_ms"`       // uniform upper bound; 0 leaves the other distributions unbounded
+}
+
+// Faults are the network imperfections of one link, applied to every message in each direction.
+type Faults struct {
+	Latency        Latency `json:"latency"`
+	Drop           float64 `json:"drop"`             // probability that a message is lost
+	Duplicate      float64 `json:"duplicate"`        // probability that a request is delivered twice
+	Reorder        float64 `json:"reorder"`          // probability that a request is held back so later ones overtake it
+	ReorderDelayMs float64 `json:"reorder_delay_ms"` // longest a reordered request is held back when no later one overtakes it
+}
+
+// Link replaces the default faults for the messages from one vehicle to another; 0 matches any vehicle.
+type Link struct {
+	From int32 `json:"from"`
+	To   int32 `json:"to"`
+	Faults
+}
+
+// Network describes the simulated V2V network. The zero value is a perfect network.
+type Network struct {
+	Faults        // default for every link
+	Links  []Link `json:"links"` // the first matching link wins
+}
+
+// Function name: LinkFaults
+// Returns the faults of the messages from one vehicle to another.
+func (n Network) LinkFaults(from int32, to int32) Faults {
+	for _, l := range n.Links {
+		if (l.From == 0 || l.From == from) && (l.To == 0 || l.To == to) {
+			return l.Faults
+		}
+	}
+	return n.Faults
+}
+
+// Function name: Enabled
+// Reports whether any fault is configured.
+func (n Network) Enabled() bool {
+	return n.Faults != (Faults{}) || len(n.Links) > 0
+}
+
+// Function name: Validate
+// Checks the default faults and every link override.
+func (n Network) Validate() error {
+	if err := n.Faults.validate("network"); err != nil {
+		return err
+	}
+	for i, l := range n.Links {
+		if l.From < 0 || l.To < 0 {
+			return fmt.Errorf("network.links[%d]: vehicle numbers must not be negative", i)
+		}
+		if err := l.Faults.validate(fmt.Sprintf("network.links[%d]", i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Function name: validate
+// Checks the probabilities and the latency distribution of one set of faults.
+func (f Faults) validate(name string) error {
+	fields := []string{"drop", "duplicate", "reorder"}
+	for i, p := range []float64{f.Drop, f.Duplicate, f.Reorder} {
+		if p < 0 || p > 1 {
+			return fmt.Errorf("%s.%s must be within [0, 1], got %v", name, fields[i], p)
+		}
+	}
+	if f.ReorderDelayMs < 0 {
+		return fmt.Errorf("%s.reorder_delay_ms must not be negative, got %v", name, f.ReorderDelayMs)
+	}
+	if f.Reorder > 0 && f.ReorderDelayMs == 0 {
+		return fmt.Errorf("%s.reorder_delay_ms must bound how long a reordered request is held, got 0", name)
+	}
+
+	l := f.Latency
+	if l.MeanMs < 0 || l.StddevMs < 0 || l.MinMs < 0 || l.MaxMs < 0 {
+		return fmt.Errorf("%s.latency values must not be negative", name)
+	}
+	switch l.Distribution {
+	case "", "none", "constant", "normal", "exponential":
+	case "uniform":
+		if l.MaxMs < l.MinMs {
+			return fmt.Errorf("%s.latency.max_ms must not be below min_ms", name)
+		}
+	default:
+		return fmt.Errorf("unknown %s.latency.distribution %q (none, constant, uniform, normal, exponential)", name, l.Distribution)
+	}
+	return nil
+}
+
+// Partition splits the vehicles of every round into groups that cannot reach each other for a time window.
+type Partition struct {
+	Sizes   []float64 `json:"sizes"`    // share of the round's vehicles in each group, e.g. [0.6, 0.4]; empty means no partition
+	StartMs int       `json:"start_ms"` // since the start of the round
+	HealMs  int       `json:"heal_ms"`  // since the start of the round; 0 means the partition lasts the whole round
+}
+
+// Function name: Enabled
+// Reports whether a partition is configured.
+func (p Partition) Enabled() bool {
+	return len(p.Sizes) > 0
+}
+
+// Function name: Validate
+// Checks the group shares and the time window.
+func (p Partition) Validate() error {
+	if !p.Enabled() {
+		return nil
+	}
+	if len(p.Sizes) < 2 {
+		return fmt.Errorf("partition.sizes needs at least two groups, got %d", len(p.Sizes))
+	}
+	var sum float64
+	for _, s := range p.Sizes {
+		if s <= 0 {
+			return fmt.Errorf("partition.sizes must be positive, got %v", s)
+		}
+		sum += s
+	}
+	if sum > 1.000001 {
+		return fmt.Errorf("partition.sizes must not add up to more than 1, got %v", sum)
+	}
+	if p.StartMs < 0 {
+		return fmt.Errorf("partition.start_ms must not be negative, got %d", p.StartMs)
+	}
+	if p.HealMs != 0 && p.HealMs <= p.StartMs {
+		return fmt.Errorf("partition.heal_ms must be after start_ms, got %d", p.HealMs)
+	}
+	return nil
+}
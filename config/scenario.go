@@ -0,0 +1,210 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Scenario describes one simulation experiment.
+// It is loaded from a JSON file so that the exact parameters of a run can be committed next to its results.
+type Scenario struct {
+	Name          string  `json:"name"`
+	TotalVehicles int     `json:"total_vehicles"` // vehicles arriving at the intersection over the whole run
+	HVRatio       float64 `json:"hv_ratio"`       // share of human-driven vehicles (never vote)
+	Lines         int     `json:"lines"`          // lanes per approach; a round holds at most lines*4 vehicles
+	VisionTimeMs  int     `json:"vision_time_ms"` // T_vision: consensus budget before the vision fallback fires
+	Quorum        string  `json:"quorum"`         // majority / unanimity / byzantine / responsive / weighted
+	RoundSize     string  `json:"round_size"`     // random (Test Mode A) / fixed (Test Mode B)
+	PassTimeMs    int     `json:"pass_time_ms"`   // time stopped HVs need to cross the intersection
+	Seed          int64   `json:"seed"`           // 0 picks a fresh seed
+	Clock         string  `json:"clock"`          // real / virtual (discrete-event, no wall-clock waits)
+	Transport     string  `json:"transport"`      // tcp / memory (in-process bufconn, no OS ports)
+	TieBreak      string  `json:"tie_break"`      // time (later ElectionTime wins) / random (RandomAgreement draw) / lamport / hlc (later logical stamp wins) / id (lower vehicle number wins)
+	Protocol      string  `json:"protocol"`       // leader (one election per group) / schedule (one election per full passing schedule)
+	Safety        string  `json:"safety"`         // report (count conflicting passes) / strict (fail the run on the first one)
+	Consensus     string  `json:"consensus"`      // crash (original election) / bft (PBFT-style, tolerates malicious vehicles)
+	Votes         string  `json:"votes"`          // plain (claimed vote counts are trusted) / signed (ed25519 votes, leaders prove a quorum certificate)
+	Security      string  `json:"security"`       // insecure (plaintext gRPC) / mtls (mutual TLS with per-vehicle certificates of a local CA)
+
+	VisionRelease     string `json:"vision_release"`      // grouped (compatible groups in plate order) / sequential (one by one)
+	VisionPassTimeMs  int    `json:"vision_pass_time_ms"` // crossing time of one vision fallback group
+	CrossTimeMs       int    `json:"cross_time_ms"`       // crossing time of one elected passing group
+	LivenessBoundMs   int    `json:"liveness_bound_ms"`   // longest acceptable wait from first round to release
+	StateDir          string `json:"state_dir"`           // durable per-vehicle vote state; empty keeps it in memory only
+	CertDir           string `json:"cert_dir"`            // mtls: where the CA and vehicle certificates are written; empty keeps them in memory only
+	DeterministicKeys bool   `json:"deterministic_keys"`  // signed votes: derive the keys from the seed instead of crypto/rand
+	ViewTimeoutMs     int    `json:"view_timeout_ms"`     // BFT: time a view is given before the replicas move to the next primary
+
+	Network     Network     `json:"network"`     // injected V2V faults; empty means a perfect network
+	Partition   Partition   `json:"partition"`   // groups of every round that cannot reach each other for a time window
+	Crash       Crash       `json:"crash"`       // CAV servers that stop at a protocol point and may restart
+	Adversaries Adversaries `json:"adversaries"` // CAVs that do not follow the protocol
+	ClockSkew   ClockSkew   `json:"clock_skew"`  // offsets of the local vehicle clocks; empty means every vehicle reads the simulation clock
+}
+
+// Function name: DefaultScenario
+// Returns the scenario that reproduces the original hard-coded experiment.
+func DefaultScenario() *Scenario {
+	return &Scenario{
+		Name:          "default",
+		TotalVehicles: 300,
+		HVRatio:       0.1,
+		Lines:         4,
+		VisionTimeMs:  500,
+		Quorum:        "majority",
+		RoundSize:     "random",
+		PassTimeMs:    3000,
+		Clock:         "real",
+		Transport:     "tcp",
+		TieBreak:      "time",
+		Protocol:      "leader",
+		Safety:        "report",
+		Consensus:     "crash",
+		Votes:         "plain",
+		Security:      "insecure",
+
+		VisionRelease:    "grouped",
+		VisionPassTimeMs: 1000,
+		CrossTimeMs:      1000,
+		LivenessBoundMs:  10000,
+		ViewTimeoutMs:    100,
+	}
+}
+
+// Function name: LoadScenario
+// Reads a JSON scenario file on top of the defaults and validates it.
+func LoadScenario(path string) (*Scenario, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open scenario: %v", err)
+	}
+	defer f.Close()
+
+	s := DefaultScenario()
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(s); err != nil {
+		return nil, fmt.Errorf("parse scenario %s: %v", path, err)
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %v", path, err)
+	}
+	return s, nil
+}
+
+// Function name: Validate
+// Checks that every parameter is within the range the simulator can run.
+func (s *Scenario) Validate() error {
+	if s.TotalVehicles <= 0 {
+		return fmt.Errorf("total_vehicles must be positive, got %d", s.TotalVehicles)
+	}
+	if s.HVRatio < 0 || s.HVRatio > 1 {
+		return fmt.Errorf("hv_ratio must be within [0, 1], got %v", s.HVRatio)
+	}
+	if s.Lines <= 0 {
+		return fmt.Errorf("lines must be positive, got %d", s.Lines)
+	}
+	if s.VisionTimeMs <= 0 {
+		return fmt.Errorf("vision_time_ms must be positive, got %d", s.VisionTimeMs)
+	}
+	if s.PassTimeMs < 0 {
+		return fmt.Errorf("pass_time_ms must not be negative, got %d", s.PassTimeMs)
+	}
+	if s.VisionPassTimeMs < 0 {
+		return fmt.Errorf("vision_pass_time_ms must not be negative, got %d", s.VisionPassTimeMs)
+	}
+	if s.CrossTimeMs < 0 {
+		return fmt.Errorf("cross_time_ms must not be negative, got %d", s.CrossTimeMs)
+	}
+	if s.LivenessBoundMs <= 0 {
+		return fmt.Errorf("liveness_bound_ms must be positive, got %d", s.LivenessBoundMs)
+	}
+	if s.ViewTimeoutMs <= 0 {
+		return fmt.Errorf("view_timeout_ms must be positive, got %d", s.ViewTimeoutMs)
+	}
+
+	if err := s.Network.Validate(); err != nil {
+		return err
+	}
+	if err := s.Partition.Validate(); err != nil {
+		return err
+	}
+	if err := s.Crash.Validate(); err != nil {
+		return err
+	}
+	if err := s.Adversaries.Validate(); err != nil {
+		return err
+	}
+	if err := s.ClockSkew.Validate(); err != nil {
+		return err
+	}
+
+	switch s.Quorum {
+	case "majority", "unanimity", "byzantine", "responsive", "weighted":
+	default:
+		return fmt.Errorf("unknown quorum %q (majority, unanimity, byzantine, responsive, weighted)", s.Quorum)
+	}
+
+	switch s.RoundSize {
+	case "random", "fixed":
+	default:
+		return fmt.Errorf("unknown round_size %q (random, fixed)", s.RoundSize)
+	}
+
+	switch s.Clock {
+	case "real", "virtual":
+	default:
+		return fmt.Errorf("unknown clock %q (real, virtual)", s.Clock)
+	}
+
+	switch s.Transport {
+	case "tcp", "memory":
+	default:
+		return fmt.Errorf("unknown transport %q (tcp, memory)", s.Transport)
+	}
+
+	switch s.TieBreak {
+	case "time", "random", "lamport", "hlc", "id":
+	default:
+		return fmt.Errorf("unknown tie_break %q (time, random, lamport, hlc, id)", s.TieBreak)
+	}
+
+	switch s.Protocol {
+	case "leader", "schedule":
+	default:
+		return fmt.Errorf("unknown protocol %q (leader, schedule)", s.Protocol)
+	}
+
+	switch s.Safety {
+	case "report", "strict":
+	default:
+		return fmt.Errorf("unknown safety %q (report, strict)", s.Safety)
+	}
+
+	switch s.Consensus {
+	case "crash", "bft":
+	default:
+		return fmt.Errorf("unknown consensus %q (crash, bft)", s.Consensus)
+	}
+
+	switch s.Votes {
+	case "plain", "signed":
+	default:
+		return fmt.Errorf("unknown votes %q (plain, signed)", s.Votes)
+	}
+
+	switch s.Security {
+	case "insecure", "mtls":
+	default:
+		return fmt.Errorf("unknown security %q (insecure, mtls)", s.Security)
+	}
+
+	switch s.VisionRelease {
+	case "grouped", "sequential":
+	default:
+		return fmt.Errorf("unknown vision_release %q (grouped, sequential)", s.VisionRelease)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package config
+
+import "fmt"
+
+// ClockSkew offsets the local clock of every vehicle from the simulation clock. A vehicle stamps its
+// ElectionTime and the physical part of its hybrid logical clock with its local clock.
+type ClockSkew struct {
+	MaxMs    int           `json:"max_ms"`   // every vehicle draws its skew uniformly from [-max_ms, max_ms]
+	Vehicles map[int32]int `json:"vehicles"` // fixed skew in ms per vehicle number, replacing the drawn one
+}
+
+// Function name: Enabled
+// Reports whether any vehicle clock is skewed.
+func (c ClockSkew) Enabled() bool {
+	return c.MaxMs > 0 || len(c.Vehicles) > 0
+}
+
+// Function name: Validate
+// Checks the skew bound and the vehicle numbers.
+func (c ClockSkew) Validate() error {
+	if c.MaxMs < 0 {
+		return fmt.Errorf("clock_skew.max_ms must not be negative, got %d", c.MaxMs)
+	}
+	for number := range c.Vehicles {
+		if number <= 0 {
+			return fmt.Errorf("clock_skew.vehicles: vehicle numbers start at 1, got %d", number)
+		}
+	}
+	return nil
+}
@@ -2,7 +2,10 @@ module main
 
 go 1.22.5
 
-require google.golang.org/grpc v1.65.0
+require (
+	google.golang.org/grpc v1.65.0
+	google.golang.org/protobuf v1.34.1
+)
 
 require (
 	gocv.io/x/gocv v0.37.0 // indirect
@@ -10,5 +13,4 @@ require (
 	golang.org/x/sys v0.20.0 // indirect
 	golang.org/x/text v0.15.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240528184218-531527333157 // indirect
-	google.golang.org/protobuf v1.34.1 // indirect
 )
@@ -0,0 +1,191 @@
+package identity
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Prefix of the common name of a vehicle certificate; the vehicle number follows it.
+const subjectPrefix = "vehicle-"
+
+// Authority is the local test CA of a run. It issues one certificate per vehicle with the vehicle
+// number bound into the subject, so a TLS peer can be mapped back to the vehicle it belongs to.
+// Vehicle 0 is the simulation itself, e.g. when it reads the final state of the servers.
+type Authority struct {
+	mu     sync.Mutex
+	cert   *x509.Certificate
+	key    ed25519.PrivateKey
+	pool   *x509.CertPool
+	serial int64
+	issued map[int32]tls.Certificate
+	dir    string // where the PEM files are written; empty keeps them in memory only
+}
+
+// Function name: NewAuthority
+// Generates the self-signed CA of a run. With a non-empty dir, the CA and every issued vehicle
+// certificate are also written there as PEM files.
+func NewAuthority(dir string) (*Authority, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "intersection test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Authority{cert: cert, key: key, pool: x509.NewCertPool(), serial: 1, issued: make(map[int32]tls.Certificate), dir: dir}
+	a.pool.AddCert(cert)
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+		if err := writePEM(filepath.Join(dir, "ca.pem"), "CERTIFICATE", der, 0o644); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// Function name: Certificate
+// Returns the certificate of the vehicle, issuing it on first use.
+func (a *Authority) Certificate(number int32) (tls.Certificate, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if cert, ok := a.issued[number]; ok {
+		return cert, nil
+	}
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	a.serial++
+	name := Subject(number)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(a.serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name, "localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, a.cert, key.Public(), a.key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	if a.dir != "" {
+		if err := a.write(name, der, key); err != nil {
+			return tls.Certificate{}, err
+		}
+	}
+
+	cert := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: crypto.Signer(key)}
+	a.issued[number] = cert
+	return cert, nil
+}
+
+// Function name: ServerConfig
+// Returns the TLS configuration of a vehicle server: it presents the vehicle certificate and
+// only accepts clients with a certificate issued by the CA.
+func (a *Authority) ServerConfig(number int32) (*tls.Config, error) {
+	cert, err := a.Certificate(number)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    a.pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS13,
+	}, nil
+}
+
+// Function name: ClientConfig
+// Returns the TLS configuration of a connection from one vehicle to another: it presents the
+// certificate of the sender and only accepts the certificate of the addressed vehicle.
+func (a *Authority) ClientConfig(from int32, to int32) (*tls.Config, error) {
+	cert, err := a.Certificate(from)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      a.pool,
+		ServerName:   Subject(to),
+		MinVersion:   tls.VersionTLS13,
+	}, nil
+}
+
+// Function name: Subject
+// Returns the common name that binds the vehicle number into its certificate.
+func Subject(number int32) string {
+	return subjectPrefix + strconv.Itoa(int(number))
+}
+
+// Function name: VehicleNumber
+// Returns the vehicle number bound into the subject of a certificate.
+func VehicleNumber(cert *x509.Certificate) (int32, error) {
+	digits, ok := strings.CutPrefix(cert.Subject.CommonName, subjectPrefix)
+	if !ok {
+		return 0, fmt.Errorf("certificate subject %q names no vehicle", cert.Subject.CommonName)
+	}
+	number, err := strconv.ParseInt(digits, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("certificate subject %q: %v", cert.Subject.CommonName, err)
+	}
+	return int32(number), nil
+}
+
+// Function name: write
+// Writes the certificate and private key of a vehicle to the directory of the CA.
+func (a *Authority) write(name string, der []byte, key ed25519.PrivateKey) error {
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err := writePEM(filepath.Join(a.dir, name+".pem"), "CERTIFICATE", der, 0o644); err != nil {
+		return err
+	}
+	return writePEM(filepath.Join(a.dir, name+"-key.pem"), "PRIVATE KEY", pkcs8, 0o600)
+}
+
+// Function name: writePEM
+// Writes one PEM block to a file.
+func writePEM(path string, kind string, der []byte, perm os.FileMode) error {
+	data := pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der})
+	if data == nil {
+		return errors.New("failed to encode " + kind)
+	}
+	return os.WriteFile(path, data, perm)
+}
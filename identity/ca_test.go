@@ -0,0 +1,117 @@
+package identity
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"testing"
+)
+
+// Function name: handshake
+// Runs a TLS handshake between a client and a server configuration over a loopback connection and
+// returns the error of each side.
+func handshake(t *testing.T, client *tls.Config, server *tls.Config) (error, error) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		defer conn.Close()
+		serverErr <- tls.Server(conn, server).Handshake()
+	}()
+	conn, err := tls.Dial("tcp", lis.Addr().String(), client)
+	if err == nil {
+		conn.Close()
+	}
+	return err, <-serverErr
+}
+
+func TestAuthorityHandshake(t *testing.T) {
+	ca, err := NewAuthority("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign, err := NewAuthority("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	must := func(c *tls.Config, err error) *tls.Config {
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	// presenting returns the configuration with the certificate of another CA in place of its own
+	presenting := func(c *tls.Config, number int32) *tls.Config {
+		cert, err := foreign.Certificate(number)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.Certificates = []tls.Certificate{cert}
+		return c
+	}
+
+	tests := []struct {
+		name   string
+		client *tls.Config
+		server *tls.Config
+		valid  bool
+	}{
+		{"same CA", must(ca.ClientConfig(1, 2)), must(ca.ServerConfig(2)), true},
+		{"client certificate of a foreign CA", presenting(must(ca.ClientConfig(1, 2)), 1), must(ca.ServerConfig(2)), false},
+		{"server certificate of a foreign CA", must(ca.ClientConfig(1, 2)), presenting(must(ca.ServerConfig(2)), 2), false},
+		{"server certificate of another vehicle", must(ca.ClientConfig(1, 2)), must(ca.ServerConfig(3)), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientErr, serverErr := handshake(t, tt.client, tt.server)
+			if valid := clientErr == nil && serverErr == nil; valid != tt.valid {
+				t.Fatalf("client error %v, server error %v, want valid = %v", clientErr, serverErr, tt.valid)
+			}
+		})
+	}
+}
+
+func TestVehicleNumber(t *testing.T) {
+	ca, err := NewAuthority("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := ca.Certificate(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if number, err := VehicleNumber(leaf); err != nil || number != 7 {
+		t.Fatalf("VehicleNumber = %d, %v, want 7", number, err)
+	}
+
+	tests := []struct {
+		name       string
+		commonName string
+	}{
+		{"no vehicle prefix", "intersection test CA"},
+		{"no number", "vehicle-"},
+		{"number out of range", "vehicle-99999999999"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaf.Subject.CommonName = tt.commonName
+			if _, err := VehicleNumber(leaf); err == nil {
+				t.Fatalf("VehicleNumber accepted %q", tt.commonName)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package identity
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+)
+
+// Ring holds the ed25519 key pair of every vehicle of a run. Every vehicle signs with its own private key and
+// checks the others with their public keys. The keys are fresh random keys, or, for a ring made with
+// NewSeededRing, derived from the run seed so that a run can be repeated bit for bit. Anybody who knows the
+// printed seed can then compute every private key, so seeded keys are only fit for reproducing experiments.
+type Ring struct {
+	mu     sync.Mutex
+	seeded bool
+	seed   int64
+	keys   map[int32]ed25519.PrivateKey
+}
+
+// Function name: NewRing
+// Creates the key ring of a run with keys from crypto/rand.
+func NewRing() *Ring {
+	return &Ring{keys: make(map[int32]ed25519.PrivateKey)}
+}
+
+// Function name: NewSeededRing
+// Creates the key ring of a run with keys derived from the run seed.
+func NewSeededRing(seed int64) *Ring {
+	return &Ring{seeded: true, seed: seed, keys: make(map[int32]ed25519.PrivateKey)}
+}
+
+// Function name: private
+// Returns the private key of the vehicle, generating it on first use.
+func (r *Ring) private(number int32) ed25519.PrivateKey {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if key, ok := r.keys[number]; ok {
+		return key
+	}
+	var seed [ed25519.SeedSize]byte
+	if r.seeded {
+		buf := make([]byte, 12)
+		binary.BigEndian.PutUint64(buf[0:8], uint64(r.seed))
+		binary.BigEndian.PutUint32(buf[8:12], uint32(number))
+		seed = sha256.Sum256(buf)
+	} else if _, err := rand.Read(seed[:]); err != nil {
+		panic(err)
+	}
+	key := ed25519.NewKeyFromSeed(seed[:])
+	r.keys[number] = key
+	return key
+}
+
+// Function name: Public
+// Returns the public key of the vehicle.
+func (r *Ring) Public(number int32) ed25519.PublicKey {
+	return r.private(number).Public().(ed25519.PublicKey)
+}
+
+// Function name: Sign
+// Signs the message with the private key of the vehicle.
+func (r *Ring) Sign(number int32, message []byte) []byte {
+	return ed25519.Sign(r.private(number), message)
+}
+
+// Function name: Verify
+// Reports whether the signature over the message was made with the key of the vehicle.
+func (r *Ring) Verify(number int32, message []byte, signature []byte) bool {
+	return len(signature) == ed25519.SignatureSize && ed25519.Verify(r.Public(number), message, signature)
+}
+
+// Function name: VoteMessage
+// Returns the bytes a voter signs when it grants its vote of a term to a candidate. Terms start again in every
+// election, so the round and the election of the round are signed too: a vote cannot be replayed in another one.
+func VoteMessage(voter int32, candidate int32, round int32, election int32, term int32) []byte {
+	buf := make([]byte, 0, 24)
+	buf = append(buf, "vote"...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(voter))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(candidate))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(round))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(election))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(term))
+	return buf
+}
+
+// Function name: PrepareMessage
+// Returns the bytes a BFT replica signs when it prepares the proposal with the digest in a view of an election.
+func PrepareMessage(replica int32, round int32, election int32, view int32, digest []byte) []byte {
+	return bftMessage("prepare", replica, round, election, view, digest)
+}
+
+// Function name: CommitMessage
+// Returns the bytes a BFT replica signs when it commits the proposal with the digest in a view of an election.
+func CommitMessage(replica int32, round int32, election int32, view int32, digest []byte) []byte {
+	return bftMessage("commit", replica, round, election, view, digest)
+}
+
+// Function name: bftMessage
+// Returns the bytes of a BFT message of the phase; the phase is signed too, so a Prepare is never a Commit.
+func bftMessage(phase string, replica int32, round int32, election int32, view int32, digest []byte) []byte {
+	buf := make([]byte, 0, len(phase)+16+len(digest))
+	buf = append(buf, phase...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(replica))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(round))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(election))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(view))
+	return append(buf, digest...)
+}
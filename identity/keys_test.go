@@ -0,0 +1,80 @@
+package identity
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestVoteMessageVerification(t *testing.T) {
+	ring := NewRing()
+	signed := VoteMessage(1, 2, 3, 1, 4)
+	signature := ring.Sign(1, signed)
+
+	tests := []struct {
+		name    string
+		signer  int32
+		message []byte
+		valid   bool
+	}{
+		{"same vote", 1, VoteMessage(1, 2, 3, 1, 4), true},
+		{"other voter", 5, VoteMessage(5, 2, 3, 1, 4), false},
+		{"other candidate", 1, VoteMessage(1, 5, 3, 1, 4), false},
+		{"other round", 1, VoteMessage(1, 2, 4, 1, 4), false},
+		{"other election", 1, VoteMessage(1, 2, 3, 2, 4), false},
+		{"other term", 1, VoteMessage(1, 2, 3, 1, 5), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ring.Verify(tt.signer, tt.message, signature); got != tt.valid {
+				t.Fatalf("Verify = %v, want %v", got, tt.valid)
+			}
+		})
+	}
+	if ring.Verify(1, signed, signature[:10]) {
+		t.Fatal("a truncated signature verified")
+	}
+}
+
+func TestRingKeys(t *testing.T) {
+	if !bytes.Equal(NewSeededRing(7).Public(3), NewSeededRing(7).Public(3)) {
+		t.Fatal("seeded rings with the same seed derived different keys")
+	}
+	if bytes.Equal(NewSeededRing(7).Public(3), NewSeededRing(8).Public(3)) {
+		t.Fatal("seeded rings with different seeds derived the same key")
+	}
+	if bytes.Equal(NewRing().Public(3), NewRing().Public(3)) {
+		t.Fatal("random rings generated the same key")
+	}
+	ring := NewRing()
+	if !bytes.Equal(ring.Public(3), ring.Public(3)) {
+		t.Fatal("a ring changed the key of a vehicle")
+	}
+}
+
+func TestCommitMessageVerification(t *testing.T) {
+	ring := NewRing()
+	signature := ring.Sign(1, CommitMessage(1, 2, 1, 3, []byte("proposal")))
+
+	tests := []struct {
+		name    string
+		signer  int32
+		message []byte
+		valid   bool
+	}{
+		{"same commit", 1, CommitMessage(1, 2, 1, 3, []byte("proposal")), true},
+		{"other replica", 4, CommitMessage(4, 2, 1, 3, []byte("proposal")), false},
+		{"other round", 1, CommitMessage(1, 3, 1, 3, []byte("proposal")), false},
+		{"other election", 1, CommitMessage(1, 2, 2, 3, []byte("proposal")), false},
+		{"other view", 1, CommitMessage(1, 2, 1, 4, []byte("proposal")), false},
+		{"other proposal", 1, CommitMessage(1, 2, 1, 3, []byte("other")), false},
+		{"vote of the same numbers", 1, VoteMessage(1, 2, 1, 3, 0), false},
+		{"prepare of the same proposal", 1, PrepareMessage(1, 2, 1, 3, []byte("proposal")), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ring.Verify(tt.signer, tt.message, signature); got != tt.valid {
+				t.Fatalf("Verify = %v, want %v", got, tt.valid)
+			}
+		})
+	}
+}
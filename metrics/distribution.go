@@ -0,0 +1,79 @@
+package metrics
+
+import "math"
+
+// Upper bounds of the default latency histogram in ms; the last bucket is open-ended.
+var DefaultBucketsMs = []float64{50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000}
+
+// Upper bounds of the RPC count histogram.
+var RPCBuckets = []float64{10, 50, 100, 250, 500, 1000}
+
+// Upper bounds of the election terms histogram.
+var TermBuckets = []float64{1, 2, 3, 5, 10}
+
+// Distribution describes a set of samples by percentiles and a histogram.
+type Distribution struct {
+	Count     int      `json:"count"`
+	Mean      float64  `json:"mean"`
+	Min       float64  `json:"min"`
+	P50       float64  `json:"p50"`
+	P90       float64  `json:"p90"`
+	P95       float64  `json:"p95"`
+	P99       float64  `json:"p99"`
+	Max       float64  `json:"max"`
+	Histogram []Bucket `json:"histogram"`
+}
+
+// Bucket counts the samples up to UpperBound (inclusive) that do not fit a smaller bucket.
+// The last bucket has an infinite bound, written as -1.
+type Bucket struct {
+	UpperBound float64 `json:"le"`
+	Count      int     `json:"count"`
+}
+
+// Function name: NewDistribution
+// Computes percentiles and a histogram over the given bucket bounds.
+func NewDistribution(values []float64, bounds []float64) Distribution {
+	d := Distribution{Count: len(values)}
+	for _, b := range bounds {
+		d.Histogram = append(d.Histogram, Bucket{UpperBound: b})
+	}
+	d.Histogram = append(d.Histogram, Bucket{UpperBound: -1})
+
+	if len(values) == 0 {
+		return d
+	}
+
+	sorted := sortedCopy(values)
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+		i := 0
+		for i < len(bounds) && v > bounds[i] {
+			i++
+		}
+		d.Histogram[i].Count++
+	}
+
+	d.Mean = sum / float64(len(sorted))
+	d.Min = sorted[0]
+	d.Max = sorted[len(sorted)-1]
+	d.P50 = Percentile(sorted, 50)
+	d.P90 = Percentile(sorted, 90)
+	d.P95 = Percentile(sorted, 95)
+	d.P99 = Percentile(sorted, 99)
+	return d
+}
+
+// Function name: Percentile
+// Returns the p-th percentile of ascending values using the nearest-rank method.
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPercentile(t *testing.T) {
+	ten := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		name   string
+		sorted []float64
+		p      float64
+		want   float64
+	}{
+		{"no samples", nil, 50, 0},
+		{"single sample", []float64{7}, 99, 7},
+		{"median of ten", ten, 50, 5},
+		{"p90 of ten", ten, 90, 9},
+		{"p99 of ten is the maximum", ten, 99, 10},
+		{"p0 is the minimum", ten, 0, 1},
+		{"p100 is the maximum", ten, 100, 10},
+		{"nearest rank rounds up", []float64{1, 2, 3}, 50, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Percentile(tt.sorted, tt.p); got != tt.want {
+				t.Fatalf("Percentile(%v) = %v, want %v", tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDistribution(t *testing.T) {
+	d := NewDistribution([]float64{120, 10, 50, 400}, []float64{50, 100})
+	if d.Count != 4 || d.Min != 10 || d.Max != 400 || d.Mean != 145 || d.P50 != 50 {
+		t.Fatalf("distribution = %+v", d)
+	}
+	want := []Bucket{{UpperBound: 50, Count: 2}, {UpperBound: 100, Count: 0}, {UpperBound: -1, Count: 2}}
+	if !reflect.DeepEqual(d.Histogram, want) {
+		t.Fatalf("histogram = %v, want %v", d.Histogram, want)
+	}
+}
@@ -0,0 +1,485 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	clock "main/clock"
+)
+
+// Round holds the measurements of one consensus round.
+type Round struct {
+	Round            int       `json:"round"`
+	Participants     int       `json:"participants"`
+	HVs              int       `json:"hvs"`
+	RPCs             int       `json:"rpcs"`
+	Leader           int32     `json:"leader"`            // first elected leader, 0 if none
+	TimeToLeaderMs   float64   `json:"time_to_leader_ms"` // from round start to the first leader, -1 if none
+	Fallback         bool      `json:"fallback"`
+	RandomTieBreaks  int       `json:"random_tie_breaks"` // LeaderElection ties decided by RandomAgreement
+	Ties             int       `json:"ties"`              // LeaderElection ties on ReceiveVotes between two candidates
+	FasterClockWins  int       `json:"faster_clock_wins"` // ties won by the candidate whose local clock runs ahead
+	Terms            int       `json:"terms"`             // election terms started in the round
+	StaleRejected    int       `json:"stale_rejected"`    // requests rejected for carrying an older term
+	Dropped          int       `json:"dropped"`           // messages lost by the injected network faults
+	Duplicated       int       `json:"duplicated"`        // requests delivered twice
+	Reordered        int       `json:"reordered"`         // requests held back so later ones overtook them
+	Partition        [][]int32 `json:"partition"`         // groups that could not reach each other, empty without a partition
+	Partitioned      int       `json:"partitioned"`       // messages lost to the partition
+	Crashes          int       `json:"crashes"`           // CAV servers that crashed at the crash point
+	Restarts         int       `json:"restarts"`          // crashed servers that came back during the election
+	DoubleVotes      int       `json:"double_votes"`      // votes a vehicle granted to a second candidate in the same term
+	InvalidCerts     int       `json:"invalid_certs"`     // vote claims rejected because their quorum certificate did not prove them
+	Spoofed          int       `json:"spoofed"`           // requests rejected because they claimed another vehicle than their TLS peer
+	Committed        [][]int32 `json:"committed"`         // passing schedule announced by the leader, one group per crossing
+	CommitAcks       int       `json:"commit_acks"`       // peers that acknowledged the committed schedule
+	SafetyViolations int       `json:"safety_violations"` // conflicting pairs inside the intersection at the same time
+	Agreement        string    `json:"agreement"`         // pass / fail over the elections of the round, empty without an election
+	Released         []int32   `json:"released"`          // vehicles that passed during the round, in order
+	FallbackGroups   [][]int32 `json:"fallback_groups"`   // vision fallback passing order, one group per crossing
+	DurationMs       float64   `json:"duration_ms"`
+}
+
+// Vehicle holds the measurements of one vehicle.
+type Vehicle struct {
+	Number      int32   `json:"number"`
+	HV          bool    `json:"hv"`
+	FirstRound  int     `json:"first_round"`
+	PassRound   int     `json:"pass_round"` // 0 if the vehicle never passed
+	Elections   int     `json:"elections"`  // elections held while the vehicle waited, 0 if it passed without one
+	ArrivalMs   float64 `json:"arrival_ms"` // since the start of the run
+	PassMs      float64 `json:"pass_ms"`
+	DelayMs     float64 `json:"delay_ms"` // arrival to pass, -1 if the vehicle never passed
+	ViaFallback bool    `json:"via_fallback"`
+	SkewMs      float64 `json:"skew_ms"` // offset of the local clock from the simulation clock
+	TiesWon     int     `json:"ties_won"`
+	TiesLost    int     `json:"ties_lost"`
+}
+
+// Recorder collects round and vehicle measurements during one run.
+// It is safe to use from the election goroutines.
+type Recorder struct {
+	mu       sync.Mutex
+	clock    clock.Clock
+	start    time.Time
+	rounds   []Round
+	current  *Round
+	started  time.Time
+	vehicles map[int32]*Vehicle
+	order    []int32
+	skews    map[int32]time.Duration
+}
+
+// Function name: NewRecorder
+// Creates a recorder whose times are measured on the given clock from now on.
+func NewRecorder(clk clock.Clock) *Recorder {
+	return &Recorder{
+		clock:    clk,
+		start:    clk.Now(),
+		vehicles: make(map[int32]*Vehicle),
+	}
+}
+
+// Function name: BeginRound
+// Opens a new round; vehicles seen for the first time are stamped as arriving now.
+func (r *Recorder) BeginRound(index int, participants []int32, hvs []int32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.Now()
+	r.current = &Round{Round: index, Participants: len(participants), HVs: len(hvs), TimeToLeaderMs: -1}
+	r.started = now
+
+	isHV := make(map[int32]bool)
+	for _, n := range hvs {
+		isHV[n] = true
+	}
+	for _, n := range participants {
+		if _, ok := r.vehicles[n]; ok {
+			continue
+		}
+		r.vehicles[n] = &Vehicle{
+			Number:     n,
+			HV:         isHV[n],
+			FirstRound: index,
+			ArrivalMs:  ms(now.Sub(r.start)),
+			DelayMs:    -1,
+			SkewMs:     ms(r.skews[n]),
+		}
+		r.order = append(r.order, n)
+	}
+}
+
+// Function name: CountRPC
+// Counts one RPC sent in the current round.
+func (r *Recorder) CountRPC() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil {
+		r.current.RPCs++
+	}
+}
+
+// Function name: Leader
+// Records that a leader was elected; only the first leader of a round sets the time to leader.
+func (r *Recorder) Leader(number int32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current == nil || r.current.Leader != 0 {
+		return
+	}
+	r.current.Leader = number
+	r.current.TimeToLeaderMs = ms(r.clock.Now().Sub(r.started))
+}
+
+// Function name: AddRandomTieBreaks
+// Counts LeaderElection ties that were decided by the RandomAgreement draw.
+func (r *Recorder) AddRandomTieBreaks(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil {
+		r.current.RandomTieBreaks += n
+	}
+}
+
+// Function name: SetSkews
+// Sets the local clock skew of every vehicle, stamped on each vehicle when it first arrives.
+func (r *Recorder) SetSkews(skews map[int32]time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.skews = skews
+}
+
+// Function name: Tie
+// Records a LeaderElection tie and whether the winner's local clock runs ahead of the loser's.
+func (r *Recorder) Tie(winner int32, loser int32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current == nil {
+		return
+	}
+	r.current.Ties++
+	if r.skews[winner] > r.skews[loser] {
+		r.current.FasterClockWins++
+	}
+	if v, ok := r.vehicles[winner]; ok {
+		v.TiesWon++
+	}
+	if v, ok := r.vehicles[loser]; ok {
+		v.TiesLost++
+	}
+}
+
+// Function name: Term
+// Records that a new election term started in the current round.
+func (r *Recorder) Term() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil {
+		r.current.Terms++
+	}
+}
+
+// Function name: Election
+// Counts one election for every participant that has not passed yet.
+func (r *Recorder) Election(participants []int32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, n := range participants {
+		if v, ok := r.vehicles[n]; ok && v.PassRound == 0 {
+			v.Elections++
+		}
+	}
+}
+
+// Function name: AddStaleRejections
+// Counts requests that the vehicle servers rejected for carrying an older term.
+func (r *Recorder) AddStaleRejections(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil {
+		r.current.StaleRejected += n
+	}
+}
+
+// Function name: Commit
+// Records the passing schedule the leader committed and how many peers acknowledged it.
+func (r *Recorder) Commit(groups [][]int32, acks int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil {
+		for _, group := range groups {
+			r.current.Committed = append(r.current.Committed, append([]int32(nil), group...))
+		}
+		r.current.CommitAcks += acks
+	}
+}
+
+// Function name: Agreement
+// Records the verdict of one election's agreement check; a round fails if any of its elections failed.
+func (r *Recorder) Agreement(pass bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current == nil {
+		return
+	}
+	if !pass {
+		r.current.Agreement = "fail"
+	} else if r.current.Agreement == "" {
+		r.current.Agreement = "pass"
+	}
+}
+
+// Function name: AddFaults
+// Counts the network faults injected during the current round.
+func (r *Recorder) AddFaults(dropped, duplicated, reordered int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil {
+		r.current.Dropped += dropped
+		r.current.Duplicated += duplicated
+		r.current.Reordered += reordered
+	}
+}
+
+// Function name: Partition
+// Records how the vehicles of the current round were partitioned.
+func (r *Recorder) Partition(groups [][]int32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil {
+		for _, group := range groups {
+			r.current.Partition = append(r.current.Partition, append([]int32(nil), group...))
+		}
+	}
+}
+
+// Function name: AddPartitioned
+// Counts messages that the partition kept from reaching their destination.
+func (r *Recorder) AddPartitioned(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil {
+		r.current.Partitioned += n
+	}
+}
+
+// Function name: AddCrashes
+// Counts the CAV servers that crashed and restarted during the current round.
+func (r *Recorder) AddCrashes(crashes, restarts int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil {
+		r.current.Crashes += crashes
+		r.current.Restarts += restarts
+	}
+}
+
+// Function name: AddDoubleVotes
+// Counts votes that vehicles granted to a second candidate of the same term.
+func (r *Recorder) AddDoubleVotes(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil {
+		r.current.DoubleVotes += n
+	}
+}
+
+// Function name: AddInvalidCerts
+// Counts vote claims that were rejected because their quorum certificate did not prove them.
+func (r *Recorder) AddInvalidCerts(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil {
+		r.current.InvalidCerts += n
+	}
+}
+
+// Function name: AddSpoofed
+// Counts requests that were rejected because they claimed another vehicle than their TLS peer.
+func (r *Recorder) AddSpoofed(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil {
+		r.current.Spoofed += n
+	}
+}
+
+// Function name: FallbackGroup
+// Records the next group released by the vision fallback.
+func (r *Recorder) FallbackGroup(group []int32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil {
+		r.current.FallbackGroups = append(r.current.FallbackGroups, append([]int32(nil), group...))
+	}
+}
+
+// Function name: Release
+// Records that the vehicles passed the intersection now.
+func (r *Recorder) Release(fallback bool, numbers ...int32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := ms(r.clock.Now().Sub(r.start))
+	for _, n := range numbers {
+		v, ok := r.vehicles[n]
+		if !ok || v.PassRound != 0 {
+			continue
+		}
+		v.PassMs = now
+		v.DelayMs = now - v.ArrivalMs
+		v.ViaFallback = fallback
+		if r.current != nil {
+			v.PassRound = r.current.Round
+			r.current.Released = append(r.current.Released, n)
+		}
+	}
+}
+
+// Function name: EndRound
+// Closes the current round and returns its measurements.
+func (r *Recorder) EndRound(fallback bool) Round {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	round := *r.current
+	round.Fallback = fallback
+	round.DurationMs = ms(r.clock.Now().Sub(r.started))
+	r.rounds = append(r.rounds, round)
+	r.current = nil
+	return round
+}
+
+// Function name: Rounds
+// Returns the measurements of every closed round.
+func (r *Recorder) Rounds() []Round {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Round(nil), r.rounds...)
+}
+
+// Function name: Vehicles
+// Returns the measurements of every vehicle in order of arrival.
+func (r *Recorder) Vehicles() []Vehicle {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	vehicles := make([]Vehicle, 0, len(r.order))
+	for _, n := range r.order {
+		vehicles = append(vehicles, *r.vehicles[n])
+	}
+	return vehicles
+}
+
+// Summary condenses the measurements of a run into distributions.
+type Summary struct {
+	RoundDuration     Distribution `json:"round_duration_ms"`
+	TimeToLeader      Distribution `json:"time_to_leader_ms"`
+	RPCsPerRound      Distribution `json:"rpcs_per_round"`
+	PassDelay         Distribution `json:"pass_delay_ms"`
+	Unreleased        int          `json:"unreleased"` // vehicles that never passed
+	RandomTieBreaks   int          `json:"random_tie_breaks"`
+	Ties              int          `json:"ties"`
+	FasterClockWins   int          `json:"faster_clock_wins"`
+	DelayFairness     float64      `json:"delay_fairness"`   // Jain's index of the pass delays: 1 when every vehicle waits equally long
+	TieBreakRounds    int          `json:"tie_break_rounds"` // rounds in which RandomAgreement decided a tie
+	TermsPerRound     Distribution `json:"terms_per_round"`
+	StaleRejected     int          `json:"stale_rejected"`
+	Dropped           int          `json:"dropped"`
+	Duplicated        int          `json:"duplicated"`
+	Reordered         int          `json:"reordered"`
+	Partitioned       int          `json:"partitioned"`
+	Crashes           int          `json:"crashes"`
+	Restarts          int          `json:"restarts"`
+	DoubleVotes       int          `json:"double_votes"`
+	InvalidCerts      int          `json:"invalid_certs"`
+	Spoofed           int          `json:"spoofed"`
+	SafetyViolations  int          `json:"safety_violations"`
+	AgreementRounds   int          `json:"agreement_rounds"`   // rounds with at least one election
+	AgreementFailures int          `json:"agreement_failures"` // rounds whose agreement check failed
+}
+
+// Function name: Summarize
+// Builds the distributions of a run from its round and vehicle measurements.
+func Summarize(rounds []Round, vehicles []Vehicle) Summary {
+	var durations, leaders, rpcs, terms, delays []float64
+	for _, round := range rounds {
+		durations = append(durations, round.DurationMs)
+		rpcs = append(rpcs, float64(round.RPCs))
+		terms = append(terms, float64(round.Terms))
+		if round.TimeToLeaderMs >= 0 {
+			leaders = append(leaders, round.TimeToLeaderMs)
+		}
+	}
+
+	var s Summary
+	for _, round := range rounds {
+		s.RandomTieBreaks += round.RandomTieBreaks
+		s.Ties += round.Ties
+		s.FasterClockWins += round.FasterClockWins
+		s.StaleRejected += round.StaleRejected
+		s.Dropped += round.Dropped
+		s.Duplicated += round.Duplicated
+		s.Reordered += round.Reordered
+		s.Partitioned += round.Partitioned
+		s.Crashes += round.Crashes
+		s.Restarts += round.Restarts
+		s.DoubleVotes += round.DoubleVotes
+		s.InvalidCerts += round.InvalidCerts
+		s.Spoofed += round.Spoofed
+		s.SafetyViolations += round.SafetyViolations
+		if round.Agreement != "" {
+			s.AgreementRounds++
+		}
+		if round.Agreement == "fail" {
+			s.AgreementFailures++
+		}
+		if round.RandomTieBreaks > 0 {
+			s.TieBreakRounds++
+		}
+	}
+	for _, v := range vehicles {
+		if v.DelayMs < 0 {
+			s.Unreleased++
+			continue
+		}
+		delays = append(delays, v.DelayMs)
+	}
+
+	s.RoundDuration = NewDistribution(durations, DefaultBucketsMs)
+	s.TimeToLeader = NewDistribution(leaders, DefaultBucketsMs)
+	s.RPCsPerRound = NewDistribution(rpcs, RPCBuckets)
+	s.TermsPerRound = NewDistribution(terms, TermBuckets)
+	s.PassDelay = NewDistribution(delays, DefaultBucketsMs)
+	s.DelayFairness = jain(delays)
+	return s
+}
+
+// Function name: jain
+// Returns Jain's fairness index of the values, (sum x)^2 / (n * sum x^2), or 1 without spread to measure.
+func jain(values []float64) float64 {
+	var sum, squares float64
+	for _, x := range values {
+		sum += x
+		squares += x * x
+	}
+	if squares == 0 {
+		return 1
+	}
+	return sum * sum / (float64(len(values)) * squares)
+}
+
+// Function name: ms
+// Converts a duration to fractional milliseconds.
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Function name: sortedCopy
+// Returns the values in ascending order without touching the input.
+func sortedCopy(values []float64) []float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return sorted
+}
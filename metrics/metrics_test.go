@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestJain(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   float64
+	}{
+		{"no samples", nil, 1},
+		{"all zero", []float64{0, 0, 0}, 1},
+		{"equal delays", []float64{5, 5, 5, 5}, 1},
+		{"one vehicle waits", []float64{10, 0, 0, 0}, 0.25},
+		{"two of four wait", []float64{10, 10, 0, 0}, 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jain(tt.values); math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("jain(%v) = %v, want %v", tt.values, got, tt.want)
+			}
+		})
+	}
+}
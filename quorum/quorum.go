@@ -0,0 +1,145 @@
+package quorum
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Round describes the vehicles of one election as the quorum policies see them.
+type Round struct {
+	Vehicles []int32       // every vehicle taking part, HVs included
+	HVs      []int32       // vehicles that never vote
+	Weights  map[int32]int // voting weight of every vehicle, used by the weighted policy
+}
+
+// Policy decides whether the vehicles supporting a candidate, the candidate included, form a quorum.
+type Policy interface {
+	Name() string
+	Reached(supporters []int32, round Round) bool
+}
+
+// Majority needs more than half of all vehicles of the round, HVs included (the original rule).
+type Majority struct{}
+
+// Unanimity needs every vehicle of the round.
+type Unanimity struct{}
+
+// Byzantine needs a Byzantine supermajority: 2f+1 of n = 3f+1 vehicles.
+// For other n it needs ceil((n+f+1)/2) with f = (n-1)/3, which still makes any two quorums share an honest vehicle.
+type Byzantine struct{}
+
+// Responsive needs more than half of the CAVs of the round; HVs, which can never vote, are not counted.
+type Responsive struct{}
+
+// Weighted needs more than half of the total voting weight of the round.
+type Weighted struct{}
+
+// Function name: New
+// Returns the policy selected by name.
+func New(name string) (Policy, error) {
+	switch name {
+	case "majority":
+		return Majority{}, nil
+	case "unanimity":
+		return Unanimity{}, nil
+	case "byzantine":
+		return Byzantine{}, nil
+	case "responsive":
+		return Responsive{}, nil
+	case "weighted":
+		return Weighted{}, nil
+	}
+	return nil, fmt.Errorf("unknown quorum %q (majority, unanimity, byzantine, responsive, weighted)", name)
+}
+
+// Function name: Name
+// Returns the scenario name of the policy.
+func (Majority) Name() string { return "majority" }
+
+// Function name: Reached
+// Reports whether the supporters are a majority of all vehicles.
+func (Majority) Reached(supporters []int32, round Round) bool {
+	return len(supporters) >= len(round.Vehicles)/2+1
+}
+
+// Function name: Name
+// Returns the scenario name of the policy.
+func (Unanimity) Name() string { return "unanimity" }
+
+// Function name: Reached
+// Reports whether every vehicle supports the candidate.
+func (Unanimity) Reached(supporters []int32, round Round) bool {
+	return len(supporters) >= len(round.Vehicles)
+}
+
+// Function name: Name
+// Returns the scenario name of the policy.
+func (Byzantine) Name() string { return "byzantine" }
+
+// Function name: Reached
+// Reports whether the supporters reach the Byzantine supermajority.
+func (Byzantine) Reached(supporters []int32, round Round) bool {
+	n := len(round.Vehicles)
+	f := (n - 1) / 3
+	return len(supporters) >= (n+f+2)/2
+}
+
+// Function name: Name
+// Returns the scenario name of the policy.
+func (Responsive) Name() string { return "responsive" }
+
+// Function name: Reached
+// Reports whether the supporters are a majority of the responsive CAVs.
+func (Responsive) Reached(supporters []int32, round Round) bool {
+	return len(supporters) >= (len(round.Vehicles)-len(round.HVs))/2+1
+}
+
+// Function name: Name
+// Returns the scenario name of the policy.
+func (Weighted) Name() string { return "weighted" }
+
+// Function name: Reached
+// Reports whether the supporters hold more than half of the total weight.
+func (Weighted) Reached(supporters []int32, round Round) bool {
+	var total, support int
+	for _, v := range round.Vehicles {
+		total += round.Weights[v]
+	}
+	for _, v := range supporters {
+		support += round.Weights[v]
+	}
+	return 2*support > total
+}
+
+// Tally collects the voters of every candidate in one election.
+// It is safe to use from the election goroutines.
+type Tally struct {
+	mu     sync.Mutex
+	voters map[int32]map[int32]bool
+}
+
+// Function name: NewTally
+// Creates an empty tally.
+func NewTally() *Tally {
+	return &Tally{voters: make(map[int32]map[int32]bool)}
+}
+
+// Function name: Add
+// Records a vote for the candidate and returns its supporters, the candidate included, in ascending order.
+func (t *Tally) Add(candidate int32, voter int32) []int32 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.voters[candidate] == nil {
+		t.voters[candidate] = map[int32]bool{candidate: true}
+	}
+	t.voters[candidate][voter] = true
+
+	supporters := make([]int32, 0, len(t.voters[candidate]))
+	for v := range t.voters[candidate] {
+		supporters = append(supporters, v)
+	}
+	sort.Slice(supporters, func(i, j int) bool { return supporters[i] < supporters[j] })
+	return supporters
+}
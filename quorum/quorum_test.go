@@ -0,0 +1,98 @@
+package quorum
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Function name: numbers
+// Returns the vehicle numbers 1..n.
+func numbers(n int) []int32 {
+	vehicles := make([]int32, n)
+	for i := range vehicles {
+		vehicles[i] = int32(i + 1)
+	}
+	return vehicles
+}
+
+func TestReached(t *testing.T) {
+	weights := map[int32]int{1: 3, 2: 1, 3: 1, 4: 1}
+	tests := []struct {
+		name       string
+		policy     Policy
+		supporters int
+		round      Round
+		want       bool
+	}{
+		{"majority of 4 needs 3", Majority{}, 2, Round{Vehicles: numbers(4)}, false},
+		{"majority of 4 reached", Majority{}, 3, Round{Vehicles: numbers(4)}, true},
+		{"majority of 5 reached", Majority{}, 3, Round{Vehicles: numbers(5)}, true},
+		{"majority counts HVs", Majority{}, 2, Round{Vehicles: numbers(4), HVs: []int32{4}}, false},
+		{"unanimity missing one", Unanimity{}, 3, Round{Vehicles: numbers(4)}, false},
+		{"unanimity reached", Unanimity{}, 4, Round{Vehicles: numbers(4)}, true},
+		{"byzantine 2f+1 of 4", Byzantine{}, 3, Round{Vehicles: numbers(4)}, true},
+		{"byzantine 2f of 4", Byzantine{}, 2, Round{Vehicles: numbers(4)}, false},
+		{"byzantine 4 of 5", Byzantine{}, 4, Round{Vehicles: numbers(5)}, true},
+		{"byzantine 3 of 5", Byzantine{}, 3, Round{Vehicles: numbers(5)}, false},
+		{"byzantine 2f+1 of 7", Byzantine{}, 5, Round{Vehicles: numbers(7)}, true},
+		{"byzantine 2f of 7", Byzantine{}, 4, Round{Vehicles: numbers(7)}, false},
+		{"responsive skips HVs", Responsive{}, 2, Round{Vehicles: numbers(4), HVs: []int32{4}}, true},
+		{"responsive needs a CAV majority", Responsive{}, 1, Round{Vehicles: numbers(4), HVs: []int32{4}}, false},
+		{"weighted heavy vehicle and one more", Weighted{}, 2, Round{Vehicles: numbers(4), Weights: weights}, true},
+		{"weighted half the weight", Weighted{}, 1, Round{Vehicles: numbers(4), Weights: weights}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Reached(numbers(tt.supporters), tt.round); got != tt.want {
+				t.Fatalf("%s.Reached(%d of %d) = %v, want %v", tt.policy.Name(), tt.supporters, len(tt.round.Vehicles), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWeightedDiffersFromMajority(t *testing.T) {
+	// vehicle 4 has waited through two elections, the others take part in their first
+	round := Round{Vehicles: numbers(4), Weights: map[int32]int{1: 1, 2: 1, 3: 1, 4: 3}}
+	tests := []struct {
+		name       string
+		supporters []int32
+		majority   bool
+		weighted   bool
+	}{
+		{"three fresh vehicles", []int32{1, 2, 3}, true, false},
+		{"the waiting vehicle and one more", []int32{2, 4}, false, true},
+		{"the waiting vehicle and two more", []int32{1, 2, 4}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Majority{}).Reached(tt.supporters, round); got != tt.majority {
+				t.Fatalf("majority = %v, want %v", got, tt.majority)
+			}
+			if got := (Weighted{}).Reached(tt.supporters, round); got != tt.weighted {
+				t.Fatalf("weighted = %v, want %v", got, tt.weighted)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range []string{"majority", "unanimity", "byzantine", "responsive", "weighted"} {
+		policy, err := New(name)
+		if err != nil || policy.Name() != name {
+			t.Fatalf("New(%q) = %v, %v", name, policy, err)
+		}
+	}
+	if _, err := New("plurality"); err == nil {
+		t.Fatal("New accepted an unknown policy")
+	}
+}
+
+func TestTally(t *testing.T) {
+	tally := NewTally()
+	tally.Add(2, 3)
+	tally.Add(5, 1)
+	tally.Add(2, 3)
+	if got := tally.Add(2, 1); !reflect.DeepEqual(got, []int32{1, 2, 3}) {
+		t.Fatalf("supporters = %v, want [1 2 3]", got)
+	}
+}
@@ -0,0 +1,37 @@
+package server
+
+import direction "main/config/directionBoolean"
+
+// Function name: strategy
+// Returns the strategy the vehicle of this server follows, empty for an honest vehicle.
+func (s *server) strategy() string {
+	return s.run.Strategies[s.Vehicle.Number]
+}
+
+// Function name: votesForAll
+// Reports whether this vehicle grants its vote to every candidate, ignoring SendVotes.
+func (s *server) votesForAll() bool {
+	strategy := s.strategy()
+	return strategy == "equivocate" || strategy == "vote_all"
+}
+
+// Function name: reportedDirection
+// Returns the direction this vehicle reports to a candidate in ReceiveRequest.
+func (s *server) reportedDirection(candidate string) string {
+	if s.strategy() == "false_direction" {
+		return FalseDirection(candidate, s.Vehicle.Direction)
+	}
+	return s.Vehicle.Direction
+}
+
+// Function name: FalseDirection
+// Returns the direction a false_direction vehicle claims towards a candidate: the first other movement
+// the candidate may cross with, so the candidate groups the two vehicles whatever the real movement is.
+func FalseDirection(candidate string, own string) string {
+	for _, d := range direction.Directions {
+		if d != own && direction.Compatible(candidate, d) {
+			return d
+		}
+	}
+	return own
+}
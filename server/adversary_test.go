@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "main/client/proto"
+	clock "main/clock"
+	direction "main/config/directionBoolean"
+	identity "main/identity"
+	verify "main/verify"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestVoteAllDetected(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		second   string // status of the vote request of a second candidate in the term
+		double   int
+	}{
+		{"honest", "", "ignored", 0},
+		{"vote_all", "vote_all", "acknowledged", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := NewRun()
+			run.Strategies = map[int32]string{2: tt.strategy}
+			s := &server{Vehicle: &pb.Vehicle{Number: 2, Term: 1}, run: run}
+			var status string
+			for _, candidate := range []int32{1, 3} {
+				r, err := s.ReceiveRequest(context.Background(), &pb.Request{Term: 1, Vehicle: &pb.Vehicle{Number: candidate, ElectionStatus: "Candidate"}})
+				if err != nil {
+					t.Fatal(err)
+				}
+				status = r.Status
+			}
+			if status != tt.second {
+				t.Fatalf("second candidate: %q, want %q", status, tt.second)
+			}
+			// the votes granted by every vehicle are checked after the election
+			if double := run.TakeDoubleVotes(); double != tt.double {
+				t.Fatalf("%d double votes, want %d", double, tt.double)
+			}
+		})
+	}
+}
+
+func TestFalseDirectionDetected(t *testing.T) {
+	var conflicts int
+	for _, candidate := range direction.Directions {
+		for _, own := range direction.Directions {
+			if direction.Compatible(candidate, own) {
+				continue
+			}
+			conflicts++
+			reported := FalseDirection(candidate, own)
+			if !direction.Compatible(candidate, reported) {
+				t.Fatalf("%s reports %s to %s, which the candidate may not cross with", own, reported, candidate)
+			}
+			// the leader groups the two vehicles, and the safety monitor sees the real movements cross
+			m := verify.NewMonitor(clock.NewVirtual(time.Now()), true)
+			m.BeginRound(1)
+			if err := m.Enter(1, candidate); err != nil {
+				t.Fatal(err)
+			}
+			if err := m.Enter(2, own); err == nil {
+				t.Fatalf("%s crossed with %s undetected", own, candidate)
+			}
+		}
+	}
+	if conflicts == 0 {
+		t.Fatal("no conflicting movements to lie about")
+	}
+}
+
+func TestInflateVotesRejected(t *testing.T) {
+	run := NewRun()
+	run.Keys = identity.NewRing()
+	vote := func(voter int32) *pb.SignedVote {
+		return (&server{Vehicle: &pb.Vehicle{Number: voter, Term: 1}, run: run, round: 1, election: 1}).signVote(1)
+	}
+	tests := []struct {
+		name        string
+		claimed     int32
+		certificate []*pb.SignedVote
+		want        string
+	}{
+		{"honest claim", 1, []*pb.SignedVote{vote(3)}, "acknowledged"},
+		{"inflate_votes", 3, []*pb.SignedVote{vote(3)}, "rejected"},
+		{"inflate_votes with a repeated vote", 3, []*pb.SignedVote{vote(3), vote(3), vote(3)}, "rejected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &server{Vehicle: &pb.Vehicle{Number: 2, Term: 1, SendVotes: 1}, run: run, round: 1, election: 1}
+			r, err := s.LeaderElection(context.Background(), &pb.Request{
+				Term:        1,
+				Vehicle:     &pb.Vehicle{Number: 1, ElectionStatus: "Candidate", ReceiveVotes: tt.claimed},
+				Certificate: tt.certificate,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Status != tt.want {
+				t.Fatalf("status = %q (%s), want %q", r.Status, r.Message, tt.want)
+			}
+			if follows := s.Vehicle.Leader == 1; follows != (tt.want == "acknowledged") {
+				t.Fatalf("follows the candidate = %v", follows)
+			}
+			if invalid := run.TakeInvalidCertificates(); (invalid > 0) != (tt.want == "rejected") {
+				t.Fatalf("%d invalid certificates counted", invalid)
+			}
+		})
+	}
+}
+
+func TestReplayTimeRejected(t *testing.T) {
+	// the candidate won term 1 with this stamp and replays it in every later election
+	replayed := &pb.Vehicle{Number: 1, ElectionStatus: "Candidate", ReceiveVotes: 3, ElectionTime: timestamppb.New(clock.Epoch)}
+	tests := []struct {
+		name  string
+		term  int32
+		want  string
+		stale int
+	}{
+		{"request of the current term", 2, "acknowledged", 0},
+		{"request replayed from an earlier term", 1, "rejected", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := NewRun()
+			s := &server{Vehicle: &pb.Vehicle{Number: 2, Term: 2, SendVotes: 1}, run: run}
+			r, err := s.LeaderElection(context.Background(), &pb.Request{Term: tt.term, Vehicle: replayed})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Status != tt.want {
+				t.Fatalf("status = %q (%s), want %q", r.Status, r.Message, tt.want)
+			}
+			if stale := run.TakeStaleRejections(); stale != tt.stale {
+				t.Fatalf("%d stale rejections, want %d", stale, tt.stale)
+			}
+			if s.Vehicle.Term != 2 {
+				t.Fatalf("vehicle moved to term %d", s.Vehicle.Term)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	pb "main/client/proto"
+)
+
+// agreement is the commit-reveal state of one RandomAgreement draw on a vehicle server.
+// The draw is only used once it is closed with the full set of committed vehicles revealed;
+// a server that missed a reveal and a server that did not would otherwise break ties differently.
+type agreement struct {
+	commitments map[int32][]byte
+	reveals     map[int32]int32
+	closed      bool
+	usable      bool
+}
+
+// Function name: Commitment
+// Returns the commitment a vehicle publishes before revealing its random number.
+func Commitment(number int32, randomNumber int32) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint32(buf[0:4], uint32(number))
+	binary.BigEndian.PutUint32(buf[4:8], uint32(randomNumber))
+	sum := sha256.Sum256(buf)
+	return sum[:]
+}
+
+// Function name: TakeRandomTieBreaks
+// Returns how many ties were decided by the random draw and resets the counter.
+func (r *Run) TakeRandomTieBreaks() int {
+	return int(r.randomTieBreaks.Swap(0))
+}
+
+// Function name: RandomAgreement
+// Runs the commit-reveal random draw among the candidates.
+// In the commit phase a vehicle sends sha256(number, random value); in the reveal phase it sends the value,
+// which is only accepted if it matches the commitment. Commitments are frozen once the first value is revealed,
+// so no vehicle can pick its value after seeing the others. The close phase ends the draw at the same point on
+// every server: it names the vehicles whose reveals make the draw, or none if a reveal was lost anywhere,
+// and later reveals are ignored. Only the coordinator of the round (the simulation, vehicle 0) closes the draw,
+// once, and the draw is only used if the roster names exactly the committed vehicles. A later term starts a new draw.
+func (s *server) RandomAgreement(ctx context.Context, req *pb.Request) (*pb.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req == nil || req.Vehicle == nil {
+		return nil, fmt.Errorf("received nil request")
+	}
+	if rejected := s.checkTerm(req); rejected != nil {
+		return rejected, nil
+	}
+
+	number := req.Vehicle.Number
+	if s.agreement.commitments == nil {
+		s.agreement.commitments = make(map[int32][]byte)
+		s.agreement.reveals = make(map[int32]int32)
+	}
+
+	switch req.Phase {
+	case "commit":
+		if len(s.agreement.reveals) > 0 {
+			return &pb.Response{
+				Message: fmt.Sprintf("Vehicle %d: commitments are closed", s.Vehicle.Number),
+				Status:  "ignored",
+			}, nil
+		}
+		if _, exists := s.agreement.commitments[number]; exists {
+			return &pb.Response{
+				Message: fmt.Sprintf("Vehicle %d: vehicle %d has already committed", s.Vehicle.Number, number),
+				Status:  "ignored",
+			}, nil
+		}
+		s.agreement.commitments[number] = req.Commitment
+		return &pb.Response{
+			Message: fmt.Sprintf("Vehicle %d: commitment of vehicle %d registered", s.Vehicle.Number, number),
+			Status:  "acknowledged",
+		}, nil
+
+	case "reveal":
+		if s.agreement.closed {
+			return &pb.Response{
+				Message: fmt.Sprintf("Vehicle %d: the draw is closed", s.Vehicle.Number),
+				Status:  "ignored",
+			}, nil
+		}
+		commitment, exists := s.agreement.commitments[number]
+		if !exists || !bytes.Equal(commitment, Commitment(number, req.RandomNumber)) {
+			return &pb.Response{
+				Message: fmt.Sprintf("Vehicle %d: reveal of vehicle %d does not match its commitment", s.Vehicle.Number, number),
+				Status:  "ignored",
+			}, nil
+		}
+		s.agreement.reveals[number] = req.RandomNumber
+		return &pb.Response{
+			Message: fmt.Sprintf("Vehicle %d: random number of vehicle %d accepted", s.Vehicle.Number, number),
+			Status:  "acknowledged",
+		}, nil
+
+	case "close":
+		if number != 0 {
+			return &pb.Response{
+				Message: fmt.Sprintf("Vehicle %d: vehicle %d may not close the draw", s.Vehicle.Number, number),
+				Status:  "rejected",
+			}, nil
+		}
+		if s.agreement.closed {
+			return &pb.Response{
+				Message: fmt.Sprintf("Vehicle %d: the draw is already closed", s.Vehicle.Number),
+				Status:  "ignored",
+			}, nil
+		}
+		s.agreement.closed = true
+		s.agreement.usable = s.agreement.complete(req.Roster)
+		if !s.agreement.usable {
+			return &pb.Response{
+				Message: fmt.Sprintf("Vehicle %d: draw closed incomplete, ties fall back to ElectionTime", s.Vehicle.Number),
+				Status:  "ignored",
+			}, nil
+		}
+		s.Vehicle.RandomNumber = s.agreement.draw()
+		return &pb.Response{
+			Message: fmt.Sprintf("Vehicle %d: draw closed", s.Vehicle.Number),
+			Status:  "acknowledged",
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown RandomAgreement phase %q", req.Phase)
+}
+
+// Function name: complete
+// Reports whether exactly the given vehicles committed and revealed their values; an empty set is never complete.
+func (a *agreement) complete(vehicles []int32) bool {
+	if len(vehicles) == 0 || len(vehicles) != len(a.reveals) || len(vehicles) != len(a.commitments) {
+		return false
+	}
+	for _, n := range vehicles {
+		if _, ok := a.reveals[n]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Function name: draw
+// Combines the revealed values into the shared random value; the result does not depend on arrival order.
+func (a *agreement) draw() int32 {
+	var numbers []int32
+	for n := range a.reveals {
+		numbers = append(numbers, n)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	var value int32
+	for _, n := range numbers {
+		value ^= a.reveals[n]
+	}
+	return value
+}
+
+// Function name: rank
+// Returns the position of a candidate in the random order defined by the shared value.
+func rank(draw int32, number int32) uint64 {
+	sum := Commitment(number, draw)
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// Function name: winsRandomTie
+// Reports whether the requesting candidate wins a tie under the random draw against the holder,
+// the candidate whose stamp this server holds (itself or the leader it follows).
+// The second result is false when the draw was not closed complete and the caller must fall back to ElectionTime.
+func (s *server) winsRandomTie(req *pb.Request, holder int32) (bool, bool) {
+	if s.run.TieBreak != "random" || !s.agreement.usable || holder == req.Vehicle.Number {
+		return false, false
+	}
+	s.run.randomTieBreaks.Add(1)
+	return rank(s.Vehicle.RandomNumber, req.Vehicle.Number) > rank(s.Vehicle.RandomNumber, holder), true
+}
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	pb "main/client/proto"
+)
+
+func TestCommitment(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  [2]int32 // (number, value) pairs
+		equal bool
+	}{
+		{"same pair", [2]int32{3, 42}, [2]int32{3, 42}, true},
+		{"other value", [2]int32{3, 42}, [2]int32{3, 43}, false},
+		{"other vehicle", [2]int32{3, 42}, [2]int32{4, 42}, false},
+		{"swapped", [2]int32{3, 42}, [2]int32{42, 3}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bytes.Equal(Commitment(tt.a[0], tt.a[1]), Commitment(tt.b[0], tt.b[1]))
+			if got != tt.equal {
+				t.Fatalf("commitments equal = %v, want %v", got, tt.equal)
+			}
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	// the rank is a pure function of the draw, so every server orders the candidates the same way
+	for _, draw := range []int32{0, 1, -7, 123456} {
+		for n := int32(1); n <= 8; n++ {
+			if rank(draw, n) != rank(draw, n) {
+				t.Fatalf("rank(%d, %d) is not stable", draw, n)
+			}
+		}
+	}
+	if rank(5, 1) == rank(5, 2) {
+		t.Fatal("different candidates share a rank")
+	}
+}
+
+// Function name: runDraw
+// Commits and reveals the given values on a fresh server, then closes the draw with the roster.
+func runDraw(t *testing.T, values map[int32]int32, revealed []int32, roster []int32) *server {
+	t.Helper()
+	s := &server{Vehicle: &pb.Vehicle{Number: 1}, run: NewRun()}
+	ctx := context.Background()
+	for n, v := range values {
+		if _, err := s.RandomAgreement(ctx, &pb.Request{Vehicle: &pb.Vehicle{Number: n}, Phase: "commit", Commitment: Commitment(n, v)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, n := range revealed {
+		if _, err := s.RandomAgreement(ctx, &pb.Request{Vehicle: &pb.Vehicle{Number: n}, Phase: "reveal", RandomNumber: values[n]}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.RandomAgreement(ctx, &pb.Request{Vehicle: &pb.Vehicle{}, Phase: "close", Roster: roster}); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestRandomAgreementClose(t *testing.T) {
+	values := map[int32]int32{1: 11, 2: 22, 3: 33}
+	tests := []struct {
+		name     string
+		revealed []int32
+		roster   []int32
+		usable   bool
+	}{
+		{"full set", []int32{1, 2, 3}, []int32{1, 2, 3}, true},
+		{"reveal order does not matter", []int32{3, 1, 2}, []int32{1, 2, 3}, true},
+		{"missed reveal", []int32{1, 2}, []int32{1, 2, 3}, false},
+		{"roster leaves out a committed vehicle", []int32{1, 2}, []int32{1, 2}, false},
+		{"closed empty", []int32{1, 2, 3}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := runDraw(t, values, tt.revealed, tt.roster)
+			if s.agreement.usable != tt.usable {
+				t.Fatalf("usable = %v, want %v", s.agreement.usable, tt.usable)
+			}
+			if tt.usable && s.Vehicle.RandomNumber != 11^22^33 {
+				t.Fatalf("draw = %d, want %d", s.Vehicle.RandomNumber, 11^22^33)
+			}
+		})
+	}
+}
+
+func TestWinsRandomTie(t *testing.T) {
+	values := map[int32]int32{1: 11, 2: 22, 3: 33}
+	s := runDraw(t, values, []int32{1, 2, 3}, []int32{1, 2, 3})
+	s.run.TieBreak = "random"
+	draw := s.Vehicle.RandomNumber
+
+	// the server is vehicle 1 but follows holder 3: the request is ranked against 3, not against 1
+	wins, ok := s.winsRandomTie(&pb.Request{Vehicle: &pb.Vehicle{Number: 2}}, 3)
+	if !ok || wins != (rank(draw, 2) > rank(draw, 3)) {
+		t.Fatalf("winsRandomTie = (%v, %v), want (%v, true)", wins, ok, rank(draw, 2) > rank(draw, 3))
+	}
+	if _, ok := s.winsRandomTie(&pb.Request{Vehicle: &pb.Vehicle{Number: 3}}, 3); ok {
+		t.Fatal("the holder itself is not a tie")
+	}
+
+	late := runDraw(t, values, []int32{1, 2}, []int32{1, 2, 3})
+	late.run.TieBreak = "random"
+	if _, ok := late.winsRandomTie(&pb.Request{Vehicle: &pb.Vehicle{Number: 2}}, 3); ok {
+		t.Fatal("an incomplete draw must fall back to ElectionTime")
+	}
+	if got := s.run.TakeRandomTieBreaks(); got != 1 {
+		t.Fatalf("TakeRandomTieBreaks = %d, want 1", got)
+	}
+}
+
+func TestRandomAgreementCloser(t *testing.T) {
+	values := map[int32]int32{1: 11, 2: 22}
+	tests := []struct {
+		name   string
+		closes []*pb.Request
+		want   []string
+		usable bool
+	}{
+		{"coordinator", []*pb.Request{{Vehicle: &pb.Vehicle{}, Phase: "close", Roster: []int32{1, 2}}}, []string{"acknowledged"}, true},
+		{"vehicle of the draw", []*pb.Request{{Vehicle: &pb.Vehicle{Number: 2}, Phase: "close", Roster: []int32{1, 2}}}, []string{"rejected"}, false},
+		{"second close", []*pb.Request{
+			{Vehicle: &pb.Vehicle{}, Phase: "close", Roster: []int32{1, 2}},
+			{Vehicle: &pb.Vehicle{}, Phase: "close"},
+		}, []string{"acknowledged", "ignored"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &server{Vehicle: &pb.Vehicle{Number: 1}, run: NewRun()}
+			ctx := context.Background()
+			for _, phase := range []string{"commit", "reveal"} {
+				for n, v := range values {
+					req := &pb.Request{Vehicle: &pb.Vehicle{Number: n}, Phase: phase, Commitment: Commitment(n, v), RandomNumber: v}
+					if _, err := s.RandomAgreement(ctx, req); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			for i, req := range tt.closes {
+				r, err := s.RandomAgreement(ctx, req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if r.Status != tt.want[i] {
+					t.Fatalf("close %d: %q (%s), want %q", i, r.Status, r.Message, tt.want[i])
+				}
+			}
+			if s.agreement.usable != tt.usable {
+				t.Fatalf("usable = %v, want %v", s.agreement.usable, tt.usable)
+			}
+		})
+	}
+}
+
+func TestRandomAgreementNewTerm(t *testing.T) {
+	values := map[int32]int32{1: 11, 2: 22, 3: 33}
+	s := runDraw(t, values, []int32{1, 2, 3}, []int32{1, 2, 3})
+	if !s.agreement.usable {
+		t.Fatal("draw is not usable")
+	}
+	// the first request of the next term, here its commit phase, starts a new draw
+	r, err := s.RandomAgreement(context.Background(), &pb.Request{Vehicle: &pb.Vehicle{Number: 2}, Phase: "commit", Commitment: Commitment(2, 44), Term: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Status != "acknowledged" {
+		t.Fatalf("commit of the new term: %q (%s)", r.Status, r.Message)
+	}
+	if s.agreement.usable || s.agreement.closed || len(s.agreement.commitments) != 1 {
+		t.Fatalf("draw of the old term survived: %+v", s.agreement)
+	}
+	// a reveal of the old term cannot reach the new draw
+	if r, _ := s.RandomAgreement(context.Background(), &pb.Request{Vehicle: &pb.Vehicle{Number: 1}, Phase: "reveal", RandomNumber: 11}); r.Status != "rejected" {
+		t.Fatalf("reveal of the old term: %q, want rejected", r.Status)
+	}
+}
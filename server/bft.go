@@ -0,0 +1,526 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	pb "main/client/proto"
+	identity "main/identity"
+	quorum "main/quorum"
+	utills "main/utills"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// bftState is the PBFT replica state of one vehicle server for one election.
+// A replica accepts one proposal per view, and once 2f+1 replicas prepared a proposal it is locked on its
+// passing groups: later views can only re-propose them, so a schedule decided in one view cannot be
+// replaced by another one in a later view. Like in Tendermint, the lock is released when 2f+1 replicas
+// prepare another proposal in a later view: that cannot happen once a schedule was decided.
+type bftState struct {
+	roster      []int32                                // replicas of the election; the primary of view v is roster[(v-1) % n]
+	view        int32                                  // current view
+	accepted    map[int32]map[string]bool              // digests of the proposals accepted per view
+	proposals   map[string]*pb.Request                 // accepted proposals, by digest
+	prepares    map[string]map[int32]*pb.SignedPrepare // signed Prepare, by view and digest, then sender
+	commits     map[string]map[int32]*pb.SignedCommit  // signed Commit, by view and digest, then sender
+	prepared    map[string]bool                        // view and digest pairs that collected 2f+1 Prepare
+	certificate []*pb.SignedCommit                     // 2f+1 signed Commit of the decided proposal
+	locked      *pb.Schedule                           // schedule of the highest prepared proposal
+	lockedView  int32
+}
+
+// Function name: ProposalDigest
+// Returns the digest a replica uses for a proposal: the schedule, including its view, and the primary's
+// vote count and ElectionTime stamp that every replica adopts when it decides.
+func ProposalDigest(schedule *pb.Schedule, primary *pb.Vehicle) []byte {
+	data, _ := proto.MarshalOptions{Deterministic: true}.Marshal(&pb.Request{
+		Schedule: schedule,
+		Vehicle: &pb.Vehicle{
+			Number:       primary.Number,
+			ReceiveVotes: primary.ReceiveVotes,
+			ElectionTime: primary.ElectionTime,
+			Lamport:      primary.Lamport,
+			Hlc:          primary.Hlc,
+		},
+	})
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+// Function name: Primary
+// Returns the primary of the view for the roster.
+func Primary(roster []int32, view int32) int32 {
+	if len(roster) == 0 || view <= 0 {
+		return 0
+	}
+	return roster[int(view-1)%len(roster)]
+}
+
+// Function name: BftRoster
+// Starts the BFT election on this replica with the roster announced by the simulation.
+func (s *server) BftRoster(ctx context.Context, req *pb.Request) (*pb.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req == nil || len(req.Roster) == 0 {
+		return nil, fmt.Errorf("received empty roster")
+	}
+	s.bft = bftState{roster: append([]int32(nil), req.Roster...)}
+	if err := s.persist(); err != nil {
+		return nil, err
+	}
+	return s.bftResponse("acknowledged", "roster of %d replicas", len(req.Roster)), nil
+}
+
+// Function name: BftViewChange
+// Moves this replica to the view of the requesting primary and returns the schedule it is locked on,
+// so the new primary re-proposes it.
+func (s *server) BftViewChange(ctx context.Context, req *pb.Request) (*pb.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rejected := s.checkView(req); rejected != nil {
+		return rejected, nil
+	}
+	if req.Vehicle.Number != Primary(s.bft.roster, req.View) {
+		return s.bftResponse("rejected", "vehicle %d is not the primary of view %d", req.Vehicle.Number, req.View), nil
+	}
+	s.enterView(req.View)
+	if err := s.persist(); err != nil {
+		return nil, err
+	}
+
+	response := s.bftResponse("acknowledged", "view %d", req.View)
+	if s.bft.locked != nil && s.strategy() != "equivocate" {
+		response.Schedule = proto.Clone(s.bft.locked).(*pb.Schedule)
+		response.View = s.bft.lockedView
+	}
+	return response, nil
+}
+
+// Function name: BftPrePrepare
+// Accepts the proposal of the primary of the view, unless this replica already accepted another proposal
+// in the view or is locked on different passing groups.
+func (s *server) BftPrePrepare(ctx context.Context, req *pb.Request) (*pb.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rejected := s.checkView(req); rejected != nil {
+		return rejected, nil
+	}
+	if rejected := s.checkProposal(req); rejected != nil {
+		return rejected, nil
+	}
+	schedule := req.Schedule
+	primary := req.Vehicle.Number
+
+	digest := hex.EncodeToString(ProposalDigest(schedule, req.Vehicle))
+	// an equivocating vehicle accepts every proposal, so it can prepare and commit all of them
+	if s.strategy() != "equivocate" {
+		for accepted := range s.bft.accepted[req.View] {
+			if accepted != digest {
+				return s.bftResponse("ignored", "vehicle %d already accepted another proposal in view %d", s.Vehicle.Number, req.View), nil
+			}
+		}
+		if s.bft.locked != nil && !sameGroups(s.bft.locked, schedule) {
+			return s.bftResponse("ignored", "vehicle %d is locked on the schedule of view %d", s.Vehicle.Number, s.bft.lockedView), nil
+		}
+	}
+
+	s.enterView(req.View)
+	if s.bft.accepted == nil {
+		s.bft.accepted = make(map[int32]map[string]bool)
+	}
+	if s.bft.proposals == nil {
+		s.bft.proposals = make(map[string]*pb.Request)
+	}
+	if s.bft.accepted[req.View] == nil {
+		s.bft.accepted[req.View] = make(map[string]bool)
+	}
+	s.bft.accepted[req.View][digest] = true
+	s.bft.proposals[digest] = &pb.Request{
+		View:     req.View,
+		Schedule: proto.Clone(schedule).(*pb.Schedule),
+		Vehicle:  proto.Clone(req.Vehicle).(*pb.Vehicle),
+	}
+
+	status := s.bftProgress(req.View, digest)
+	if err := s.persist(); err != nil {
+		return nil, err
+	}
+	if err := s.crashPoint("vote"); err != nil {
+		return nil, err
+	}
+	response := s.bftResponse(status, "vehicle %d accepted the proposal of %d in view %d", s.Vehicle.Number, primary, req.View)
+	response.Digest, _ = hex.DecodeString(digest)
+	response.Prepare = s.signPrepare(req.View, response.Digest)
+	s.attachCommits(response, req.View, digest)
+	return response, nil
+}
+
+// Function name: BftPrepare
+// Records a signed Prepare from another replica. The response is "prepared" when this replica just collected
+// 2f+1 Prepare for a proposal it accepted, and "committed" when it also decided.
+func (s *server) BftPrepare(ctx context.Context, req *pb.Request) (*pb.Response, error) {
+	return s.bftVote(req, "Prepare")
+}
+
+// Function name: BftCommit
+// Records a signed Commit from another replica. The response is "committed" when this replica just decided:
+// it prepared the proposal and collected 2f+1 Commit for it.
+func (s *server) BftCommit(ctx context.Context, req *pb.Request) (*pb.Response, error) {
+	return s.bftVote(req, "Commit")
+}
+
+// Function name: bftVote
+// Records a Prepare or Commit of a replica of the roster and advances this replica.
+func (s *server) bftVote(req *pb.Request, phase string) (*pb.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rejected := s.checkView(req); rejected != nil {
+		return rejected, nil
+	}
+	if !utills.Contains(s.bft.roster, req.Vehicle.Number) {
+		return s.bftResponse("rejected", "vehicle %d is not a replica", req.Vehicle.Number), nil
+	}
+
+	digest := hex.EncodeToString(req.Digest)
+	key := voteKey(req.View, digest)
+	unlocked := false
+	if phase == "Commit" {
+		if !s.validCommit(req.Commit, req.View, req.Digest) || req.Commit.Replica != req.Vehicle.Number {
+			s.run.invalidCertificates.Add(1)
+			return s.bftResponse("rejected", "Commit of vehicle %d is not signed by it", req.Vehicle.Number), nil
+		}
+		if s.bft.commits == nil {
+			s.bft.commits = make(map[string]map[int32]*pb.SignedCommit)
+		}
+		if s.bft.commits[key] == nil {
+			s.bft.commits[key] = make(map[int32]*pb.SignedCommit)
+		}
+		s.bft.commits[key][req.Vehicle.Number] = req.Commit
+	} else {
+		if !s.validPrepare(req.Prepare, req.View, req.Digest) || req.Prepare.Replica != req.Vehicle.Number {
+			s.run.invalidCertificates.Add(1)
+			return s.bftResponse("rejected", "Prepare of vehicle %d is not signed by it", req.Vehicle.Number), nil
+		}
+		if s.bft.prepares == nil {
+			s.bft.prepares = make(map[string]map[int32]*pb.SignedPrepare)
+		}
+		if s.bft.prepares[key] == nil {
+			s.bft.prepares[key] = make(map[int32]*pb.SignedPrepare)
+		}
+		s.bft.prepares[key][req.Vehicle.Number] = req.Prepare
+		unlocked = s.unlock(req.View, key)
+	}
+
+	status := s.bftProgress(req.View, digest)
+	if status != "acknowledged" || unlocked {
+		if err := s.persist(); err != nil {
+			return nil, err
+		}
+	}
+	if status == "committed" {
+		if err := s.crashPoint("commit"); err != nil {
+			return nil, err
+		}
+	}
+	response := s.bftResponse(status, "%s of vehicle %d recorded", phase, req.Vehicle.Number)
+	s.attachCommits(response, req.View, digest)
+	return response, nil
+}
+
+// Function name: unlock
+// Releases the lock of this replica once 2f+1 replicas sent Prepare for a proposal of a later view than the
+// lock. A decided schedule was prepared by f+1 honest replicas that refuse any other groups, so no other
+// groups can collect these Prepare after it. Reports whether the lock was released. Must be called with s.mu held.
+func (s *server) unlock(view int32, key string) bool {
+	if s.bft.locked == nil || view <= s.bft.lockedView || !s.bftQuorum(senders(s.bft.prepares[key])) {
+		return false
+	}
+	s.bft.locked = nil
+	s.bft.lockedView = 0
+	return true
+}
+
+// Function name: bftProgress
+// Moves the accepted proposal to prepared once 2f+1 replicas sent Prepare for it, and decides it once it is
+// prepared and 2f+1 replicas sent Commit. Returns the step that was just taken. Must be called with s.mu held.
+func (s *server) bftProgress(view int32, digest string) string {
+	request, ok := s.bft.proposals[digest]
+	if !ok || !s.bft.accepted[view][digest] {
+		return "acknowledged"
+	}
+
+	key := voteKey(view, digest)
+	status := "acknowledged"
+	if !s.bft.prepared[key] && s.bftQuorum(senders(s.bft.prepares[key])) {
+		if s.bft.prepared == nil {
+			s.bft.prepared = make(map[string]bool)
+		}
+		s.bft.prepared[key] = true
+		if view >= s.bft.lockedView {
+			s.bft.locked = proto.Clone(request.Schedule).(*pb.Schedule)
+			s.bft.lockedView = view
+		}
+		status = "prepared"
+	}
+
+	if s.bft.prepared[key] && s.schedule == nil && s.bftQuorum(senders(s.bft.commits[key])) {
+		s.decide(request)
+		for _, commit := range s.bft.commits[key] {
+			s.bft.certificate = append(s.bft.certificate, commit)
+		}
+		status = "committed"
+	}
+	return status
+}
+
+// Function name: BftDecided
+// Lets a replica that missed Prepare or Commit messages catch up: it decides a proposal of the primary of the
+// view once the proposal comes with a commit certificate, 2f+1 Commit of replicas of the roster signed with
+// their keys, so at least f+1 honest replicas prepared it.
+func (s *server) BftDecided(ctx context.Context, req *pb.Request) (*pb.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rejected := s.checkView(req); rejected != nil {
+		return rejected, nil
+	}
+	if rejected := s.checkProposal(req); rejected != nil {
+		return rejected, nil
+	}
+	if s.schedule != nil {
+		return s.bftResponse("acknowledged", "vehicle %d already decided", s.Vehicle.Number), nil
+	}
+
+	digest := ProposalDigest(req.Schedule, req.Vehicle)
+	committed := make(map[int32]*pb.SignedCommit)
+	for _, commit := range req.Commits {
+		if s.validCommit(commit, req.View, digest) {
+			committed[commit.Replica] = commit
+		}
+	}
+	if !s.bftQuorum(senders(committed)) {
+		s.run.invalidCertificates.Add(1)
+		return s.bftResponse("rejected", "certificate proves %d Commit for view %d", len(committed), req.View), nil
+	}
+	s.decide(req)
+	for _, commit := range committed {
+		s.bft.certificate = append(s.bft.certificate, commit)
+	}
+	if err := s.persist(); err != nil {
+		return nil, err
+	}
+	response := s.bftResponse("committed", "vehicle %d caught up with view %d", s.Vehicle.Number, req.View)
+	response.Commits = s.bft.certificate
+	return response, nil
+}
+
+// Function name: checkProposal
+// Rejects a proposal that is not the primary's proposal for the view, or whose schedule is invalid.
+// Must be called with s.mu held.
+func (s *server) checkProposal(req *pb.Request) *pb.Response {
+	schedule := req.Schedule
+	primary := Primary(s.bft.roster, req.View)
+	if schedule == nil || req.Vehicle.Number != primary || schedule.Leader != primary || schedule.Term != req.View {
+		return s.bftResponse("rejected", "not a proposal of primary %d for view %d", primary, req.View)
+	}
+	if err := s.checkSchedule(schedule); err != nil {
+		return s.bftResponse("rejected", "%v", err)
+	}
+	return nil
+}
+
+// Function name: signPrepare
+// Returns this replica's signed Prepare for the proposal, nil without a key ring.
+func (s *server) signPrepare(view int32, digest []byte) *pb.SignedPrepare {
+	if s.run.Keys == nil {
+		return nil
+	}
+	number := s.Vehicle.Number
+	return &pb.SignedPrepare{
+		Replica:   number,
+		Round:     s.round,
+		Election:  s.election,
+		View:      view,
+		Digest:    digest,
+		Signature: s.run.Keys.Sign(number, identity.PrepareMessage(number, s.round, s.election, view, digest)),
+	}
+}
+
+// Function name: validPrepare
+// Reports whether the Prepare was signed by a replica of the roster for the proposal in this election.
+func (s *server) validPrepare(prepare *pb.SignedPrepare, view int32, digest []byte) bool {
+	if s.run.Keys == nil || prepare == nil || !utills.Contains(s.bft.roster, prepare.Replica) {
+		return false
+	}
+	if prepare.Round != s.round || prepare.Election != s.election || prepare.View != view || !bytes.Equal(prepare.Digest, digest) {
+		return false
+	}
+	message := identity.PrepareMessage(prepare.Replica, prepare.Round, prepare.Election, prepare.View, prepare.Digest)
+	return s.run.Keys.Verify(prepare.Replica, message, prepare.Signature)
+}
+
+// Function name: signCommit
+// Returns this replica's signed Commit for the proposal, nil without a key ring.
+func (s *server) signCommit(view int32, digest []byte) *pb.SignedCommit {
+	if s.run.Keys == nil {
+		return nil
+	}
+	number := s.Vehicle.Number
+	return &pb.SignedCommit{
+		Replica:   number,
+		Round:     s.round,
+		Election:  s.election,
+		View:      view,
+		Digest:    digest,
+		Signature: s.run.Keys.Sign(number, identity.CommitMessage(number, s.round, s.election, view, digest)),
+	}
+}
+
+// Function name: validCommit
+// Reports whether the Commit was signed by a replica of the roster for the proposal in this election.
+func (s *server) validCommit(commit *pb.SignedCommit, view int32, digest []byte) bool {
+	if s.run.Keys == nil || commit == nil || !utills.Contains(s.bft.roster, commit.Replica) {
+		return false
+	}
+	if commit.Round != s.round || commit.Election != s.election || commit.View != view || !bytes.Equal(commit.Digest, digest) {
+		return false
+	}
+	message := identity.CommitMessage(commit.Replica, commit.Round, commit.Election, commit.View, commit.Digest)
+	return s.run.Keys.Verify(commit.Replica, message, commit.Signature)
+}
+
+// Function name: attachCommits
+// Adds this replica's signed Commit to a response once it prepared the proposal, or right away for an
+// equivocating replica, and the commit certificate once it decided. Must be called with s.mu held.
+func (s *server) attachCommits(response *pb.Response, view int32, digest string) {
+	if s.bft.prepared[voteKey(view, digest)] || (s.strategy() == "equivocate" && s.bft.accepted[view][digest]) {
+		raw, _ := hex.DecodeString(digest)
+		response.Commit = s.signCommit(view, raw)
+	}
+	if response.Status == "committed" {
+		response.Commits = s.bft.certificate
+	}
+}
+
+// Function name: decide
+// Stores the decided schedule of the proposal. Must be called with s.mu held.
+func (s *server) decide(request *pb.Request) {
+	s.schedule = proto.Clone(request.Schedule).(*pb.Schedule)
+	if s.Vehicle.Number == request.Schedule.Leader {
+		s.Vehicle.ElectionStatus = "Leader"
+	} else {
+		s.Vehicle.ElectionStatus = "Follower"
+	}
+	// like CommitSchedule, every replica adopts the primary's vote count and ElectionTime
+	s.Vehicle.Leader = request.Schedule.Leader
+	s.Vehicle.ReceiveVotes = request.Vehicle.ReceiveVotes
+	adoptStamp(s.Vehicle, request.Vehicle)
+}
+
+// Function name: bftQuorum
+// Reports whether the senders are 2f+1 of the roster.
+func (s *server) bftQuorum(supporters []int32) bool {
+	return quorum.Byzantine{}.Reached(supporters, quorum.Round{Vehicles: s.bft.roster})
+}
+
+// Function name: senders
+// Returns the senders of a Prepare or Commit set.
+func senders[V any](votes map[int32]V) []int32 {
+	numbers := make([]int32, 0, len(votes))
+	for number := range votes {
+		numbers = append(numbers, number)
+	}
+	return numbers
+}
+
+// Function name: checkView
+// Rejects a BFT message before the roster is known, without a sender, or from a view this replica left.
+// Must be called with s.mu held.
+func (s *server) checkView(req *pb.Request) *pb.Response {
+	if req == nil || req.Vehicle == nil {
+		return s.bftResponse("rejected", "received nil request")
+	}
+	if len(s.bft.roster) == 0 {
+		return s.bftResponse("rejected", "vehicle %d has no roster", s.Vehicle.Number)
+	}
+	if req.View < s.bft.view || req.View <= 0 {
+		return s.bftResponse("rejected", "vehicle %d is in view %d, request is from view %d", s.Vehicle.Number, s.bft.view, req.View)
+	}
+	return nil
+}
+
+// Function name: checkSchedule
+// Checks that a proposed schedule only holds replicas of the roster, each at most once, in non-empty groups.
+func (s *server) checkSchedule(schedule *pb.Schedule) error {
+	seen := make(map[int32]bool)
+	for _, group := range schedule.Groups {
+		if len(group.Vehicles) == 0 {
+			return fmt.Errorf("empty passing group")
+		}
+		for _, number := range group.Vehicles {
+			if !utills.Contains(s.bft.roster, number) || seen[number] {
+				return fmt.Errorf("vehicle %d is not a replica or appears twice", number)
+			}
+			seen[number] = true
+		}
+	}
+	return nil
+}
+
+// Function name: enterView
+// Moves this replica to a later view; its term follows the view so GetState reports it.
+func (s *server) enterView(view int32) {
+	if view > s.bft.view {
+		s.bft.view = view
+		s.Vehicle.Term = view
+	}
+}
+
+// Function name: bftResponse
+// Builds a BFT response carrying this replica's state and current view.
+func (s *server) bftResponse(status string, format string, args ...any) *pb.Response {
+	return &pb.Response{
+		Message: fmt.Sprintf(format, args...),
+		Status:  status,
+		Vehicle: proto.Clone(s.Vehicle).(*pb.Vehicle),
+		View:    s.bft.view,
+	}
+}
+
+// Function name: sameGroups
+// Reports whether two schedules let the same vehicles pass in the same groups and order.
+func sameGroups(a *pb.Schedule, b *pb.Schedule) bool {
+	if len(a.Groups) != len(b.Groups) {
+		return false
+	}
+	for i := range a.Groups {
+		x := append([]int32(nil), a.Groups[i].Vehicles...)
+		y := append([]int32(nil), b.Groups[i].Vehicles...)
+		if len(x) != len(y) {
+			return false
+		}
+		sort.Slice(x, func(i, j int) bool { return x[i] < x[j] })
+		sort.Slice(y, func(i, j int) bool { return y[i] < y[j] })
+		for k := range x {
+			if x[k] != y[k] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Function name: voteKey
+// Returns the key of the Prepare and Commit sets of a proposal.
+func voteKey(view int32, digest string) string {
+	return fmt.Sprintf("%d/%s", view, digest)
+}
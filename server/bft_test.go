@@ -0,0 +1,185 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	pb "main/client/proto"
+	identity "main/identity"
+)
+
+func TestBftDecided(t *testing.T) {
+	run := &Run{Keys: identity.NewRing()}
+
+	roster := []int32{1, 2, 3, 4}
+	primary := &pb.Vehicle{Number: 1, Address: 1}
+	schedule := &pb.Schedule{Term: 1, Leader: 1, Groups: []*pb.PassGroup{{Vehicles: []int32{1, 2}}, {Vehicles: []int32{3, 4}}}}
+	digest := ProposalDigest(schedule, primary)
+	commit := func(replica int32, election int32) *pb.SignedCommit {
+		s := &server{Vehicle: &pb.Vehicle{Number: replica}, run: run, round: 1, election: election}
+		return s.signCommit(1, digest)
+	}
+	forged := commit(4, 1)
+	forged.Replica = 5
+	stranger := &pb.Schedule{Term: 1, Leader: 2, Groups: schedule.Groups}
+
+	tests := []struct {
+		name     string
+		vehicle  *pb.Vehicle
+		schedule *pb.Schedule
+		commits  []*pb.SignedCommit
+		want     string
+	}{
+		{"2f+1 signed Commit", primary, schedule, []*pb.SignedCommit{commit(1, 1), commit(2, 1), commit(3, 1)}, "committed"},
+		{"f+1 signed Commit", primary, schedule, []*pb.SignedCommit{commit(1, 1), commit(2, 1)}, "rejected"},
+		{"duplicate replica", primary, schedule, []*pb.SignedCommit{commit(1, 1), commit(2, 1), commit(2, 1)}, "rejected"},
+		{"other election", primary, schedule, []*pb.SignedCommit{commit(1, 1), commit(2, 1), commit(3, 2)}, "rejected"},
+		{"forged replica", primary, schedule, []*pb.SignedCommit{commit(1, 1), commit(2, 1), forged}, "rejected"},
+		{"no certificate", primary, schedule, nil, "rejected"},
+		{"not the primary", &pb.Vehicle{Number: 2, Address: 2}, stranger, []*pb.SignedCommit{commit(1, 1), commit(2, 1), commit(3, 1)}, "rejected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &server{Vehicle: &pb.Vehicle{Number: 3}, run: run, round: 1, election: 1, bft: bftState{roster: roster}}
+			r, err := s.BftDecided(context.Background(), &pb.Request{Sender: 2, Vehicle: tt.vehicle, View: 1, Schedule: tt.schedule, Commits: tt.commits})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Status != tt.want {
+				t.Fatalf("status = %q (%s), want %q", r.Status, r.Message, tt.want)
+			}
+			if decided := s.schedule != nil; decided != (tt.want == "committed") {
+				t.Fatalf("decided = %v, want %v", decided, tt.want == "committed")
+			}
+		})
+	}
+}
+
+func TestBftUnlock(t *testing.T) {
+	run := &Run{Keys: identity.NewRing()}
+	locked := &pb.Schedule{Term: 1, Leader: 1, Groups: []*pb.PassGroup{{Vehicles: []int32{1, 2, 3, 4}}}}
+	tests := []struct {
+		name     string
+		current  int32 // view the locked replica is in
+		view     int32 // view of the Prepare
+		senders  []int32
+		unlocked bool
+	}{
+		{"2f+1 Prepare in a later view", 2, 2, []int32{1, 2, 4}, true},
+		{"f+1 Prepare in a later view", 2, 2, []int32{1, 2}, false},
+		{"2f+1 Prepare in the view of the lock", 1, 1, []int32{1, 2, 4}, false},
+		{"2f+1 Prepare of a view the replica left", 3, 2, []int32{1, 2, 4}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &server{Vehicle: &pb.Vehicle{Number: 3}, run: run, round: 1, election: 1, bft: bftState{roster: []int32{1, 2, 3, 4}, view: tt.current, locked: locked, lockedView: 1}}
+			for _, k := range tt.senders {
+				prepare := (&server{Vehicle: &pb.Vehicle{Number: k}, run: run, round: 1, election: 1}).signPrepare(tt.view, []byte("other"))
+				if _, err := s.BftPrepare(context.Background(), &pb.Request{Vehicle: &pb.Vehicle{Number: k}, View: tt.view, Digest: []byte("other"), Prepare: prepare}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if unlocked := s.bft.locked == nil; unlocked != tt.unlocked {
+				t.Fatalf("unlocked = %v, want %v", unlocked, tt.unlocked)
+			}
+		})
+	}
+}
+
+func TestBftCommitSignature(t *testing.T) {
+	run := &Run{Keys: identity.NewRing()}
+	digest := []byte("proposal")
+	signed := (&server{Vehicle: &pb.Vehicle{Number: 2}, run: run, round: 1, election: 1}).signCommit(1, digest)
+	tests := []struct {
+		name   string
+		sender int32
+		commit *pb.SignedCommit
+		want   string
+	}{
+		{"own signature", 2, signed, "acknowledged"},
+		{"signature of another replica", 4, signed, "rejected"},
+		{"unsigned", 2, nil, "rejected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &server{Vehicle: &pb.Vehicle{Number: 3}, run: run, round: 1, election: 1, bft: bftState{roster: []int32{1, 2, 3, 4}, view: 1}}
+			r, err := s.BftCommit(context.Background(), &pb.Request{Vehicle: &pb.Vehicle{Number: tt.sender}, View: 1, Digest: digest, Commit: tt.commit})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Status != tt.want {
+				t.Fatalf("status = %q (%s), want %q", r.Status, r.Message, tt.want)
+			}
+		})
+	}
+}
+
+func TestBftPrepareSignature(t *testing.T) {
+	run := &Run{Keys: identity.NewRing()}
+	digest := []byte("proposal")
+	signed := (&server{Vehicle: &pb.Vehicle{Number: 2}, run: run, round: 1, election: 1}).signPrepare(1, digest)
+	// a relay that cannot sign for vehicle 2 puts its own signature on a Prepare in its name
+	fabricated := (&server{Vehicle: &pb.Vehicle{Number: 4}, run: run, round: 1, election: 1}).signPrepare(1, digest)
+	fabricated.Replica = 2
+	tests := []struct {
+		name    string
+		sender  int32
+		prepare *pb.SignedPrepare
+		want    string
+	}{
+		{"own signature", 2, signed, "acknowledged"},
+		{"signature of another replica", 4, signed, "rejected"},
+		{"fabricated for another replica", 2, fabricated, "rejected"},
+		{"unsigned", 2, nil, "rejected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &server{Vehicle: &pb.Vehicle{Number: 3}, run: run, round: 1, election: 1, bft: bftState{roster: []int32{1, 2, 3, 4}, view: 1}}
+			r, err := s.BftPrepare(context.Background(), &pb.Request{Vehicle: &pb.Vehicle{Number: tt.sender}, View: 1, Digest: digest, Prepare: tt.prepare})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Status != tt.want {
+				t.Fatalf("status = %q (%s), want %q", r.Status, r.Message, tt.want)
+			}
+			if recorded := len(s.bft.prepares) > 0; recorded != (tt.want == "acknowledged") {
+				t.Fatalf("recorded = %v, want %v", recorded, tt.want == "acknowledged")
+			}
+		})
+	}
+}
+
+func TestBftEquivocation(t *testing.T) {
+	primary := &pb.Vehicle{Number: 1, Address: 1}
+	first := &pb.Schedule{Term: 1, Leader: 1, Groups: []*pb.PassGroup{{Vehicles: []int32{1, 2}}, {Vehicles: []int32{3, 4}}}}
+	second := &pb.Schedule{Term: 1, Leader: 1, Groups: []*pb.PassGroup{{Vehicles: []int32{3, 4}}, {Vehicles: []int32{1, 2}}}}
+	tests := []struct {
+		name     string
+		strategy string
+		want     string
+	}{
+		{"honest replica keeps the first proposal", "", "ignored"},
+		{"equivocating replica accepts both", "equivocate", "acknowledged"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := &Run{Keys: identity.NewRing(), Strategies: map[int32]string{3: tt.strategy}}
+			s := &server{Vehicle: &pb.Vehicle{Number: 3}, run: run, round: 1, election: 1, bft: bftState{roster: []int32{1, 2, 3, 4}}}
+			r, err := s.BftPrePrepare(context.Background(), &pb.Request{Vehicle: primary, View: 1, Schedule: first})
+			if err != nil || r.Status != "acknowledged" || r.Prepare == nil {
+				t.Fatalf("first proposal: %v, %v", r, err)
+			}
+			r, err = s.BftPrePrepare(context.Background(), &pb.Request{Vehicle: primary, View: 1, Schedule: second})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Status != tt.want {
+				t.Fatalf("status = %q (%s), want %q", r.Status, r.Message, tt.want)
+			}
+			// a replica signs a Prepare only for the proposals it accepted
+			if signed := r.Prepare != nil; signed != (tt.want == "acknowledged") {
+				t.Fatalf("signed Prepare = %v, want %v", signed, tt.want == "acknowledged")
+			}
+		})
+	}
+}
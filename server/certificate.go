@@ -0,0 +1,111 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+
+	pb "main/client/proto"
+	identity "main/identity"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Function name: TakeInvalidCertificates
+// Returns how many claims were rejected for an invalid certificate and resets the counter.
+func (r *Run) TakeInvalidCertificates() int {
+	return int(r.invalidCertificates.Swap(0))
+}
+
+// Function name: signVote
+// Returns this vehicle's signed vote for the candidate in its current election and term, nil with plain votes.
+func (s *server) signVote(candidate int32) *pb.SignedVote {
+	if s.run.Keys == nil {
+		return nil
+	}
+	number := s.Vehicle.Number
+	term := s.Vehicle.Term
+	return &pb.SignedVote{
+		Voter:     number,
+		Candidate: candidate,
+		Round:     s.round,
+		Election:  s.election,
+		Term:      term,
+		Signature: s.run.Keys.Sign(number, identity.VoteMessage(number, candidate, s.round, s.election, term)),
+	}
+}
+
+// Function name: Certified
+// Returns how many distinct voters, other than the candidate, signed a valid vote for the candidate in the
+// given election and term. Votes of another round or election of the run do not count.
+func (r *Run) Certified(certificate []*pb.SignedVote, candidate int32, round int32, election int32, term int32) int32 {
+	return int32(len(r.certifiedVoters(certificate, candidate, round, election, term)))
+}
+
+// Function name: certifiedVoters
+// Returns the distinct voters, other than the candidate, that signed a valid vote for the candidate in the
+// given election and term, in ascending order.
+func (r *Run) certifiedVoters(certificate []*pb.SignedVote, candidate int32, round int32, election int32, term int32) []int32 {
+	if r.Keys == nil {
+		return nil
+	}
+	voters := make(map[int32]bool)
+	for _, vote := range certificate {
+		if vote == nil || vote.Candidate != candidate || vote.Voter == candidate {
+			continue
+		}
+		if vote.Round != round || vote.Election != election || vote.Term != term {
+			continue
+		}
+		message := identity.VoteMessage(vote.Voter, vote.Candidate, vote.Round, vote.Election, vote.Term)
+		if r.Keys.Verify(vote.Voter, message, vote.Signature) {
+			voters[vote.Voter] = true
+		}
+	}
+	certified := make([]int32, 0, len(voters))
+	for voter := range voters {
+		certified = append(certified, voter)
+	}
+	sort.Slice(certified, func(i, j int) bool { return certified[i] < certified[j] })
+	return certified
+}
+
+// Function name: checkCertificate
+// With signed votes, rejects a candidate whose claimed ReceiveVotes are not backed by its quorum certificate:
+// every claimed vote must be a valid signature of a distinct voter for this candidate, election and term.
+// Must be called with s.mu held.
+func (s *server) checkCertificate(req *pb.Request) *pb.Response {
+	if s.run.Keys == nil {
+		return nil
+	}
+	certified := s.run.Certified(req.Certificate, req.Vehicle.Number, s.round, s.election, req.Term)
+	if certified >= req.Vehicle.ReceiveVotes {
+		return nil
+	}
+	s.run.invalidCertificates.Add(1)
+	return &pb.Response{
+		Message: fmt.Sprintf("Vehicle %d claims %d votes, its certificate proves %d", req.Vehicle.Number, req.Vehicle.ReceiveVotes, certified),
+		Status:  "rejected",
+		Vehicle: proto.Clone(s.Vehicle).(*pb.Vehicle),
+	}
+}
+
+// Function name: checkElected
+// With signed votes, rejects a schedule whose leader cannot prove its election: its certificate must hold valid
+// votes of this election and term from voters that, together with the leader, reach the quorum of the run.
+// Must be called with s.mu held.
+func (s *server) checkElected(req *pb.Request) *pb.Response {
+	if s.run.Keys == nil {
+		return nil
+	}
+	leader := req.Schedule.Leader
+	voters := s.run.certifiedVoters(req.Certificate, leader, s.round, s.election, req.Term)
+	if s.run.Quorum.Reached(append(voters, leader), s.roster) {
+		return nil
+	}
+	s.run.invalidCertificates.Add(1)
+	return &pb.Response{
+		Message: fmt.Sprintf("Vehicle %d announced a schedule, its certificate proves %d votes of term %d", leader, len(voters), req.Term),
+		Status:  "rejected",
+		Vehicle: proto.Clone(s.Vehicle).(*pb.Vehicle),
+	}
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"testing"
+
+	pb "main/client/proto"
+	identity "main/identity"
+)
+
+func TestCertified(t *testing.T) {
+	run := &Run{Keys: identity.NewRing()}
+	vote := func(voter int32, round int32, election int32, term int32) *pb.SignedVote {
+		s := &server{Vehicle: &pb.Vehicle{Number: voter, Term: term}, run: run, round: round, election: election}
+		return s.signVote(2)
+	}
+	forged := vote(4, 1, 1, 1)
+	forged.Voter = 5
+
+	tests := []struct {
+		name        string
+		certificate []*pb.SignedVote
+		want        int32
+	}{
+		{"distinct voters", []*pb.SignedVote{vote(1, 1, 1, 1), vote(3, 1, 1, 1)}, 2},
+		{"duplicate voter", []*pb.SignedVote{vote(1, 1, 1, 1), vote(1, 1, 1, 1)}, 1},
+		{"own vote", []*pb.SignedVote{vote(2, 1, 1, 1)}, 0},
+		{"earlier election of the round", []*pb.SignedVote{vote(1, 1, 0, 1), vote(3, 1, 1, 1)}, 1},
+		{"earlier round", []*pb.SignedVote{vote(1, 0, 1, 1)}, 0},
+		{"other term", []*pb.SignedVote{vote(1, 1, 1, 2)}, 0},
+		{"forged voter", []*pb.SignedVote{forged}, 0},
+		{"missing vote", []*pb.SignedVote{nil}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := run.Certified(tt.certificate, 2, 1, 1, 1); got != tt.want {
+				t.Fatalf("Certified = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"time"
+
+	pb "main/client/proto"
+	clock "main/clock"
+
+	"google.golang.org/protobuf/proto"
+	pbtimestamp "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Tie is one LeaderElection tie on ReceiveVotes and the candidate that won it.
+type Tie struct {
+	Winner int32
+	Loser  int32
+}
+
+// Function name: ResetClocks
+// Starts the vehicle clocks of a run at zero with the given skews; vehicles without a skew read the simulation clock.
+func (r *Run) ResetClocks(skews map[int32]time.Duration) {
+	r.clocks.mu.Lock()
+	defer r.clocks.mu.Unlock()
+
+	r.clocks.skews = skews
+	r.clocks.lamports = make(map[int32]*clock.Lamport)
+	r.clocks.hlcs = make(map[int32]*clock.HLC)
+}
+
+// Function name: LocalTime
+// Returns the current time on the skewed local clock of the vehicle.
+func (r *Run) LocalTime(number int32) time.Time {
+	r.clocks.mu.Lock()
+	skew := r.clocks.skews[number]
+	r.clocks.mu.Unlock()
+	return r.Clock.Now().Add(skew)
+}
+
+// Function name: logicalClocks
+// Returns the Lamport and hybrid logical clock of the vehicle, creating them on first use.
+func (r *Run) logicalClocks(number int32) (*clock.Lamport, *clock.HLC) {
+	r.clocks.mu.Lock()
+	defer r.clocks.mu.Unlock()
+
+	if r.clocks.lamports == nil {
+		r.clocks.lamports = make(map[int32]*clock.Lamport)
+		r.clocks.hlcs = make(map[int32]*clock.HLC)
+	}
+	if _, ok := r.clocks.lamports[number]; !ok {
+		r.clocks.lamports[number] = &clock.Lamport{}
+		r.clocks.hlcs[number] = &clock.HLC{}
+	}
+	return r.clocks.lamports[number], r.clocks.hlcs[number]
+}
+
+// Function name: Stamp
+// Stamps the vehicle record with the clocks of the given vehicle: its local ElectionTime and the
+// next reading of its Lamport and hybrid logical clock.
+func (r *Run) Stamp(number int32, v *pb.Vehicle) {
+	lamport, hlc := r.logicalClocks(number)
+	now := r.LocalTime(number)
+	wall, logical := hlc.Tick(now.UnixNano())
+
+	v.ElectionTime = pbtimestamp.New(now)
+	v.Lamport = lamport.Tick()
+	v.Hlc = &pb.HybridTime{Wall: wall, Logical: logical}
+}
+
+// Function name: Observe
+// Moves the logical clocks of the given vehicle past the stamp of a vehicle record it received.
+func (r *Run) Observe(number int32, v *pb.Vehicle) {
+	if v == nil {
+		return
+	}
+	lamport, hlc := r.logicalClocks(number)
+	lamport.Observe(v.Lamport)
+	hlc.Observe(r.LocalTime(number).UnixNano(), v.Hlc.GetWall(), v.Hlc.GetLogical())
+}
+
+// Function name: adoptStamp
+// Copies the ElectionTime and the logical stamps of one vehicle record into another.
+func adoptStamp(dst *pb.Vehicle, src *pb.Vehicle) {
+	dst.ElectionTime = src.ElectionTime
+	dst.Lamport = src.Lamport
+	dst.Hlc = nil
+	if src.Hlc != nil {
+		dst.Hlc = proto.Clone(src.Hlc).(*pb.HybridTime)
+	}
+}
+
+// Function name: laterStamp
+// Reports whether stamp a of vehicle aNumber wins a tie against stamp b of vehicle bNumber under the tie-break of the run.
+// lamport and hlc keep the later logical stamp and the lower vehicle number on equal stamps, id keeps the
+// lower vehicle number, and time (also the fallback of random) keeps the later ElectionTime.
+func (r *Run) laterStamp(a *pb.Vehicle, aNumber int32, b *pb.Vehicle, bNumber int32) bool {
+	switch r.TieBreak {
+	case "lamport":
+		if a.Lamport != b.Lamport {
+			return a.Lamport > b.Lamport
+		}
+		return aNumber < bNumber
+	case "hlc":
+		if a.Hlc.GetWall() != b.Hlc.GetWall() {
+			return a.Hlc.GetWall() > b.Hlc.GetWall()
+		}
+		if a.Hlc.GetLogical() != b.Hlc.GetLogical() {
+			return a.Hlc.GetLogical() > b.Hlc.GetLogical()
+		}
+		return aNumber < bNumber
+	case "id":
+		return aNumber < bNumber
+	}
+	return a.ElectionTime.AsTime().UnixNano() > b.ElectionTime.AsTime().UnixNano()
+}
+
+// Function name: recordTie
+// Records the outcome of a LeaderElection tie.
+func (r *Run) recordTie(winner int32, loser int32) {
+	r.ties.mu.Lock()
+	defer r.ties.mu.Unlock()
+	r.ties.decided = append(r.ties.decided, Tie{Winner: winner, Loser: loser})
+}
+
+// Function name: TakeTies
+// Returns the ties decided since the last call and forgets them.
+func (r *Run) TakeTies() []Tie {
+	r.ties.mu.Lock()
+	defer r.ties.mu.Unlock()
+
+	decided := r.ties.decided
+	r.ties.decided = nil
+	return decided
+}
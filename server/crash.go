@@ -0,0 +1,130 @@
+package server
+
+import (
+	"log"
+	"time"
+
+	pb "main/client/proto"
+	utills "main/utills"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// CrashPlan decides which CAV servers crash during an election and whether they come back.
+type CrashPlan struct {
+	Probability  float64       // chance of crashing at the crash point
+	Point        string        // candidate / vote / follow / commit / any
+	Restart      string        // none / with_state / without_state
+	RestartAfter time.Duration // downtime before the restart
+	Rand         *utills.LockedRand
+}
+
+// Function name: Down
+// Reports whether the server of the vehicle is crashed and not restarted yet.
+func (r *Run) Down(number int32) bool {
+	r.crashes.mu.Lock()
+	defer r.crashes.mu.Unlock()
+	return r.crashes.down[number]
+}
+
+// Function name: StopRecovered
+// Stops the servers that restarted during the election, forgets the crashed vehicles
+// and returns how many crashes and restarts happened since the last call.
+func (r *Run) StopRecovered() (int, int) {
+	r.crashes.mu.Lock()
+	recovered := r.crashes.recovered
+	count, restarts := r.crashes.count, r.crashes.restarts
+	r.crashes.generation++
+	r.crashes.crashed = nil
+	r.crashes.down = nil
+	r.crashes.recovered = nil
+	r.crashes.count = 0
+	r.crashes.restarts = 0
+	r.crashes.mu.Unlock()
+
+	for _, grpcServer := range recovered {
+		grpcServer.GracefulStop()
+	}
+	return count, restarts
+}
+
+// Function name: crashPoint
+// Called by a handler right after the vehicle passed the given protocol point. With the probability of the plan
+// the server crashes there: it is stopped, the response of the current request is lost, and the vehicle is
+// restarted later if the plan says so. Returns the error the handler must return when it crashed.
+func (s *server) crashPoint(point string) error {
+	plan := s.run.Crash
+	if plan == nil || (plan.Point != point && plan.Point != "any") {
+		return nil
+	}
+
+	crashes := &s.run.crashes
+	crashes.mu.Lock()
+	number := s.Vehicle.Number
+	if crashes.crashed[number] || plan.Rand.Float64() >= plan.Probability {
+		crashes.mu.Unlock()
+		return nil
+	}
+	if crashes.crashed == nil {
+		crashes.crashed = make(map[int32]bool)
+		crashes.down = make(map[int32]bool)
+	}
+	crashes.crashed[number] = true
+	crashes.down[number] = true
+	crashes.count++
+	generation := crashes.generation
+	crashes.mu.Unlock()
+
+	go s.crash(plan, generation)
+	return status.Errorf(codes.Unavailable, "vehicle %d crashed after %s", number, point)
+}
+
+// Function name: crash
+// Stops the server and, for crash-recovery, serves the vehicle again on the same address after the downtime,
+// either with the state it had when it crashed or as a fresh candidate that forgot its votes.
+func (s *server) crash(plan *CrashPlan, generation int) {
+	s.grpcServer.Stop()
+	if plan.Restart == "none" {
+		return
+	}
+
+	s.mu.Lock()
+	vehicle := proto.Clone(s.Vehicle).(*pb.Vehicle)
+	var schedule *pb.Schedule
+	if s.schedule != nil {
+		schedule = proto.Clone(s.schedule).(*pb.Schedule)
+	}
+	bft := s.bft
+	draw := s.agreement
+	s.mu.Unlock()
+
+	s.run.Clock.Sleep(plan.RestartAfter)
+
+	// without state the process memory is gone: only what was persisted to the state directory comes back
+	restarted := newServer(s.run, s.address, vehicle.Direction, vehicle.Number, vehicle.LicensePlate, "Candidate")
+	restarted.round = s.round
+	restarted.election = s.election
+	restarted.roster = s.roster
+	if plan.Restart == "with_state" {
+		restarted.Vehicle = vehicle
+		restarted.schedule = schedule
+		restarted.bft = bft
+		restarted.agreement = draw
+	}
+
+	crashes := &s.run.crashes
+	crashes.mu.Lock()
+	defer crashes.mu.Unlock()
+	if generation != crashes.generation {
+		return
+	}
+	if err := restarted.serve(); err != nil {
+		log.Printf("failed to restart vehicle %d: %v", vehicle.Number, err)
+		return
+	}
+	crashes.recovered = append(crashes.recovered, restarted.grpcServer)
+	crashes.down[vehicle.Number] = false
+	crashes.restarts++
+}
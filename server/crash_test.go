@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "main/client/proto"
+	clock "main/clock"
+	quorum "main/quorum"
+	transport "main/transport"
+	utills "main/utills"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCrashPoints(t *testing.T) {
+	candidate := &pb.Vehicle{Number: 1, Address: 1, ElectionStatus: "Candidate", ReceiveVotes: 3}
+	points := []struct {
+		point   string
+		trigger func(pb.VehicleServiceClient, context.Context) error // request that passes the crash point; nil when starting does
+		kept    func(*pb.Vehicle) bool                               // whether the vehicle still has the state it reached at the point
+	}{
+		{"candidate", nil, func(v *pb.Vehicle) bool { return v.ElectionStatus == "Candidate" }},
+		{"vote", func(c pb.VehicleServiceClient, ctx context.Context) error {
+			_, err := c.ReceiveRequest(ctx, &pb.Request{Vehicle: candidate})
+			return err
+		}, func(v *pb.Vehicle) bool { return v.SendVotes == 1 }},
+		{"follow", func(c pb.VehicleServiceClient, ctx context.Context) error {
+			_, err := c.LeaderElection(ctx, &pb.Request{Vehicle: candidate})
+			return err
+		}, func(v *pb.Vehicle) bool { return v.Leader == 1 }},
+		{"commit", func(c pb.VehicleServiceClient, ctx context.Context) error {
+			_, err := c.CommitSchedule(ctx, &pb.Request{Vehicle: candidate, Schedule: &pb.Schedule{Leader: 1, Groups: []*pb.PassGroup{{Vehicles: []int32{1, 2}}}}})
+			return err
+		}, func(v *pb.Vehicle) bool { return v.Leader == 1 }},
+	}
+	restarts := []string{"none", "with_state", "without_state"}
+
+	for _, p := range points {
+		for _, restart := range restarts {
+			t.Run(p.point+"/"+restart, func(t *testing.T) {
+				run := NewRun()
+				run.Clock = clock.Real{}
+				run.Transport = transport.NewMemory()
+				run.Crash = &CrashPlan{Probability: 1, Point: p.point, Restart: restart, RestartAfter: 20 * time.Millisecond, Rand: utills.NewLockedStream(1, "crash")}
+				grpcServer, _ := run.StartServer(2, "Ll", 2, "plate", "Candidate", 1, 1, quorum.Round{Vehicles: []int32{1, 2}})
+				defer grpcServer.Stop()
+				defer run.StopRecovered()
+
+				call := func(method func(pb.VehicleServiceClient, context.Context) error) error {
+					conn, err := run.Transport.Dial(2)
+					if err != nil {
+						t.Fatal(err)
+					}
+					defer conn.Close()
+					ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+					defer cancel()
+					return method(pb.NewVehicleServiceClient(conn), ctx)
+				}
+				var state *pb.Vehicle
+				getState := func(c pb.VehicleServiceClient, ctx context.Context) error {
+					r, err := c.GetState(ctx, &pb.Request{})
+					if err == nil {
+						state = r.Vehicle
+					}
+					return err
+				}
+
+				// the response of the request that crashed the vehicle is lost
+				if p.trigger != nil {
+					if err := call(p.trigger); status.Code(err) != codes.Unavailable {
+						t.Fatalf("request at the crash point: %v, want Unavailable", err)
+					}
+				}
+				if !run.Down(2) {
+					t.Fatal("vehicle is not down after its crash point")
+				}
+
+				// a crash-stop vehicle is given a few times its downtime to show that it stays down
+				wait := 2 * time.Second
+				if restart == "none" {
+					wait = 5 * run.Crash.RestartAfter
+				}
+				deadline := time.Now().Add(wait)
+				for run.Down(2) && time.Now().Before(deadline) {
+					time.Sleep(5 * time.Millisecond)
+				}
+				if restart == "none" {
+					if !run.Down(2) {
+						t.Fatal("crash-stop vehicle came back")
+					}
+					if err := call(getState); err == nil {
+						t.Fatal("crash-stop vehicle answered")
+					}
+					if crashes, restarts := run.StopRecovered(); crashes != 1 || restarts != 0 {
+						t.Fatalf("%d crashes, %d restarts, want 1, 0", crashes, restarts)
+					}
+					return
+				}
+
+				if run.Down(2) {
+					t.Fatal("vehicle did not restart")
+				}
+				if err := call(getState); err != nil {
+					t.Fatal(err)
+				}
+				if kept := p.kept(state); kept != (restart == "with_state" || p.point == "candidate") {
+					t.Fatalf("restarted %s with state %v, kept = %v", restart, state, kept)
+				}
+				// a vehicle crashes at most once per election
+				if p.trigger != nil {
+					if err := call(p.trigger); err != nil {
+						t.Fatalf("request after the restart: %v", err)
+					}
+				}
+				if crashes, restarts := run.StopRecovered(); crashes != 1 || restarts != 1 {
+					t.Fatalf("%d crashes, %d restarts, want 1, 1", crashes, restarts)
+				}
+			})
+		}
+	}
+}
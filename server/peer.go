@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+
+	pb "main/client/proto"
+	identity "main/identity"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Function name: TakeSpoofed
+// Returns how many requests were rejected for a spoofed vehicle number and resets the counter.
+func (r *Run) TakeSpoofed() int {
+	return int(r.spoofed.Swap(0))
+}
+
+// Function name: securityOptions
+// Returns the server options that make the vehicle server require mutual TLS and check the sender
+// of every request against its certificate; none without a CA.
+func (s *server) securityOptions() ([]grpc.ServerOption, error) {
+	if s.run.CA == nil {
+		return nil, nil
+	}
+	tlsConfig, err := s.run.CA.ServerConfig(s.address)
+	if err != nil {
+		return nil, err
+	}
+	return []grpc.ServerOption{
+		grpc.Creds(credentials.NewTLS(tlsConfig)),
+		grpc.ChainUnaryInterceptor(s.run.authenticate),
+	}, nil
+}
+
+// Methods the simulation itself (vehicle 0) calls to set up and read the servers; it claims no vehicle of its own.
+var simulationMethods = map[string]bool{
+	pb.VehicleService_GetState_FullMethodName:        true,
+	pb.VehicleService_BftRoster_FullMethodName:       true,
+	pb.VehicleService_RandomAgreement_FullMethodName: true,
+}
+
+// Function name: authenticate
+// Rejects a request whose claimed sender is not the vehicle of the TLS peer certificate, and a request
+// of the simulation (vehicle 0) to any method but the ones it sets up and reads the servers with.
+func (r *Run) authenticate(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	number, err := peerVehicle(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	request, ok := req.(*pb.Request)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unexpected request %T", req)
+	}
+	if claimed := sender(request); claimed != number {
+		r.spoofed.Add(1)
+		return nil, status.Errorf(codes.PermissionDenied, "vehicle %d sent a request as vehicle %d", number, claimed)
+	}
+	if number == 0 && !simulationMethods[info.FullMethod] {
+		r.spoofed.Add(1)
+		return nil, status.Errorf(codes.PermissionDenied, "the simulation may not call %s", info.FullMethod)
+	}
+	return handler(ctx, req)
+}
+
+// Function name: peerVehicle
+// Returns the vehicle number bound into the verified certificate of the TLS peer.
+func peerVehicle(ctx context.Context) (int32, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return 0, status.Error(codes.Unauthenticated, "no peer")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return 0, status.Error(codes.Unauthenticated, "peer has no verified certificate")
+	}
+	return identity.VehicleNumber(tlsInfo.State.VerifiedChains[0][0])
+}
+
+// Function name: sender
+// Returns the vehicle a request claims to come from: the reporting replica of BftDecided, the
+// requesting vehicle otherwise.
+func sender(req *pb.Request) int32 {
+	if req.Sender != 0 {
+		return req.Sender
+	}
+	return req.Vehicle.GetNumber()
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "main/client/proto"
+	identity "main/identity"
+	transport "main/transport"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthenticate(t *testing.T) {
+	ca, err := identity.NewAuthority("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := NewRun()
+	run.Transport = transport.NewMemory()
+	run.CA = ca
+	s := &server{Vehicle: &pb.Vehicle{Number: 2, Address: 2}, run: run, address: 2}
+	if err := s.serve(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.grpcServer.Stop()
+
+	// call sends the request to vehicle 2 over mutual TLS with the certificate of the sender
+	call := func(from int32, method func(pb.VehicleServiceClient, context.Context) error) error {
+		tlsConfig, err := ca.ClientConfig(from, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, err := run.Transport.Dial(2, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		return method(pb.NewVehicleServiceClient(conn), ctx)
+	}
+	getState := func(claimed int32) func(pb.VehicleServiceClient, context.Context) error {
+		return func(c pb.VehicleServiceClient, ctx context.Context) error {
+			_, err := c.GetState(ctx, &pb.Request{Vehicle: &pb.Vehicle{Number: claimed}})
+			return err
+		}
+	}
+	updateVoteCount := func(claimed int32) func(pb.VehicleServiceClient, context.Context) error {
+		return func(c pb.VehicleServiceClient, ctx context.Context) error {
+			_, err := c.UpdateVoteCount(ctx, &pb.Request{Vehicle: &pb.Vehicle{Number: claimed}})
+			return err
+		}
+	}
+
+	tests := []struct {
+		name   string
+		from   int32
+		method func(pb.VehicleServiceClient, context.Context) error
+		want   codes.Code
+	}{
+		{"vehicle as itself", 1, getState(1), codes.OK},
+		{"vehicle as another vehicle", 1, getState(3), codes.PermissionDenied},
+		{"simulation reads the state", 0, getState(0), codes.OK},
+		{"simulation as a vehicle", 0, getState(3), codes.PermissionDenied},
+		{"simulation in an election", 0, updateVoteCount(0), codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(call(tt.from, tt.method)); got != tt.want {
+				t.Fatalf("code = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if spoofed := run.TakeSpoofed(); spoofed != 3 {
+		t.Fatalf("%d requests counted as spoofed, want 3", spoofed)
+	}
+}
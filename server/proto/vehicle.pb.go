@@ -38,10 +38,14 @@ type Vehicle struct {
 	ReceiveVotes   int32                  `protobuf:"varint,5,opt,name=receive_votes,json=receiveVotes,proto3" json:"receive_votes,omitempty"`       // number of votes received
 	Covehicle      []*Vehicle             `protobuf:"bytes,6,rep,name=covehicle,proto3" json:"covehicle,omitempty"`                                  // vehicles in the same direction group
 	RandomNumber   int32                  `protobuf:"varint,7,opt,name=random_number,json=randomNumber,proto3" json:"random_number,omitempty"`       // random number for consensus simulation
-	LicensePlate   int32                  `protobuf:"varint,8,opt,name=license_plate,json=licensePlate,proto3" json:"license_plate,omitempty"`       // license plate (optional field)
+	LicensePlate   string                 `protobuf:"bytes,8,opt,name=license_plate,json=licensePlate,proto3" json:"license_plate,omitempty"`        // license plate, read by the vision fallback
 	ElectionTime   *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=election_time,json=electionTime,proto3" json:"election_time,omitempty"`        // timestamp when elected as leader
 	ElectionVote   int32                  `protobuf:"varint,10,opt,name=election_vote,json=electionVote,proto3" json:"election_vote,omitempty"`      // votes received in leader election
 	ElectionStatus string                 `protobuf:"bytes,11,opt,name=election_status,json=electionStatus,proto3" json:"election_status,omitempty"` // Candidate / Follower
+	Term           int32                  `protobuf:"varint,12,opt,name=term,proto3" json:"term,omitempty"`                                          // election term the vehicle is in
+	Leader         int32                  `protobuf:"varint,13,opt,name=leader,proto3" json:"leader,omitempty"`                                      // leader this vehicle followed, 0 if none
+	Lamport        int64                  `protobuf:"varint,14,opt,name=lamport,proto3" json:"lamport,omitempty"`                                    // Lamport clock of the ElectionTime stamp
+	Hlc            *HybridTime            `protobuf:"bytes,15,opt,name=hlc,proto3" json:"hlc,omitempty"`                                             // hybrid logical clock of the ElectionTime stamp
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
@@ -125,11 +129,11 @@ func (x *Vehicle) GetRandomNumber() int32 {
 	return 0
 }
 
-func (x *Vehicle) GetLicensePlate() int32 {
+func (x *Vehicle) GetLicensePlate() string {
 	if x != nil {
 		return x.LicensePlate
 	}
-	return 0
+	return ""
 }
 
 func (x *Vehicle) GetElectionTime() *timestamppb.Timestamp {
@@ -153,6 +157,87 @@ func (x *Vehicle) GetElectionStatus() string {
 	return ""
 }
 
+func (x *Vehicle) GetTerm() int32 {
+	if x != nil {
+		return x.Term
+	}
+	return 0
+}
+
+func (x *Vehicle) GetLeader() int32 {
+	if x != nil {
+		return x.Leader
+	}
+	return 0
+}
+
+func (x *Vehicle) GetLamport() int64 {
+	if x != nil {
+		return x.Lamport
+	}
+	return 0
+}
+
+func (x *Vehicle) GetHlc() *HybridTime {
+	if x != nil {
+		return x.Hlc
+	}
+	return nil
+}
+
+// HybridTime message definition: a hybrid logical clock reading
+type HybridTime struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Wall          int64                  `protobuf:"varint,1,opt,name=wall,proto3" json:"wall,omitempty"`       // highest physical time seen, UnixNano of a vehicle's local clock
+	Logical       int32                  `protobuf:"varint,2,opt,name=logical,proto3" json:"logical,omitempty"` // counter ordering events with the same wall time
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HybridTime) Reset() {
+	*x = HybridTime{}
+	mi := &file_vehicle_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HybridTime) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HybridTime) ProtoMessage() {}
+
+func (x *HybridTime) ProtoReflect() protoreflect.Message {
+	mi := &file_vehicle_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HybridTime.ProtoReflect.Descriptor instead.
+func (*HybridTime) Descriptor() ([]byte, []int) {
+	return file_vehicle_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *HybridTime) GetWall() int64 {
+	if x != nil {
+		return x.Wall
+	}
+	return 0
+}
+
+func (x *HybridTime) GetLogical() int32 {
+	if x != nil {
+		return x.Logical
+	}
+	return 0
+}
+
 // Request message definition
 type Request struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -160,13 +245,25 @@ type Request struct {
 	Port          string                 `protobuf:"bytes,2,opt,name=Port,proto3" json:"Port,omitempty"`                                         // port of the sender
 	TotalVehicles int32                  `protobuf:"varint,3,opt,name=total_vehicles,json=totalVehicles,proto3" json:"total_vehicles,omitempty"` // total number of vehicles involved
 	RandomNumber  int32                  `protobuf:"varint,4,opt,name=RandomNumber,proto3" json:"RandomNumber,omitempty"`                        // random value for test simulation
+	Commitment    []byte                 `protobuf:"bytes,5,opt,name=commitment,proto3" json:"commitment,omitempty"`                             // RandomAgreement commitment: sha256(number, random value)
+	Phase         string                 `protobuf:"bytes,6,opt,name=phase,proto3" json:"phase,omitempty"`                                       // RandomAgreement phase: commit / reveal / close
+	Term          int32                  `protobuf:"varint,7,opt,name=term,proto3" json:"term,omitempty"`                                        // election term of the sender
+	Schedule      *Schedule              `protobuf:"bytes,8,opt,name=schedule,proto3" json:"schedule,omitempty"`                                 // passing schedule announced by the leader
+	View          int32                  `protobuf:"varint,9,opt,name=view,proto3" json:"view,omitempty"`                                        // BFT view of the sender
+	Digest        []byte                 `protobuf:"bytes,10,opt,name=digest,proto3" json:"digest,omitempty"`                                    // BFT proposal the Prepare or Commit is for
+	Roster        []int32                `protobuf:"varint,11,rep,packed,name=roster,proto3" json:"roster,omitempty"`                            // BFT replicas of the election, in primary order; RandomAgreement close: vehicles whose reveals make the draw
+	Sender        int32                  `protobuf:"varint,12,opt,name=sender,proto3" json:"sender,omitempty"`                                   // BFT replica reporting its decision; vehicle then holds the primary
+	Certificate   []*SignedVote          `protobuf:"bytes,13,rep,name=certificate,proto3" json:"certificate,omitempty"`                          // signed votes backing the ReceiveVotes the candidate claims
+	Commit        *SignedCommit          `protobuf:"bytes,14,opt,name=commit,proto3" json:"commit,omitempty"`                                    // BFT Commit of the sender, signed with its key
+	Commits       []*SignedCommit        `protobuf:"bytes,15,rep,name=commits,proto3" json:"commits,omitempty"`                                  // BFT commit certificate: 2f+1 signed Commit of the decided proposal
+	Prepare       *SignedPrepare         `protobuf:"bytes,16,opt,name=prepare,proto3" json:"prepare,omitempty"`                                  // BFT Prepare of the sender, signed with its key
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Request) Reset() {
 	*x = Request{}
-	mi := &file_vehicle_proto_msgTypes[1]
+	mi := &file_vehicle_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -178,7 +275,7 @@ func (x *Request) String() string {
 func (*Request) ProtoMessage() {}
 
 func (x *Request) ProtoReflect() protoreflect.Message {
-	mi := &file_vehicle_proto_msgTypes[1]
+	mi := &file_vehicle_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -191,7 +288,7 @@ func (x *Request) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Request.ProtoReflect.Descriptor instead.
 func (*Request) Descriptor() ([]byte, []int) {
-	return file_vehicle_proto_rawDescGZIP(), []int{1}
+	return file_vehicle_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *Request) GetVehicle() *Vehicle {
@@ -222,6 +319,451 @@ func (x *Request) GetRandomNumber() int32 {
 	return 0
 }
 
+func (x *Request) GetCommitment() []byte {
+	if x != nil {
+		return x.Commitment
+	}
+	return nil
+}
+
+func (x *Request) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
+func (x *Request) GetTerm() int32 {
+	if x != nil {
+		return x.Term
+	}
+	return 0
+}
+
+func (x *Request) GetSchedule() *Schedule {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+func (x *Request) GetView() int32 {
+	if x != nil {
+		return x.View
+	}
+	return 0
+}
+
+func (x *Request) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *Request) GetRoster() []int32 {
+	if x != nil {
+		return x.Roster
+	}
+	return nil
+}
+
+func (x *Request) GetSender() int32 {
+	if x != nil {
+		return x.Sender
+	}
+	return 0
+}
+
+func (x *Request) GetCertificate() []*SignedVote {
+	if x != nil {
+		return x.Certificate
+	}
+	return nil
+}
+
+func (x *Request) GetCommit() *SignedCommit {
+	if x != nil {
+		return x.Commit
+	}
+	return nil
+}
+
+func (x *Request) GetCommits() []*SignedCommit {
+	if x != nil {
+		return x.Commits
+	}
+	return nil
+}
+
+func (x *Request) GetPrepare() *SignedPrepare {
+	if x != nil {
+		return x.Prepare
+	}
+	return nil
+}
+
+// SignedPrepare message definition: a BFT Prepare signed with the replica's ed25519 key
+type SignedPrepare struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Replica       int32                  `protobuf:"varint,1,opt,name=replica,proto3" json:"replica,omitempty"`    // replica that prepares the proposal
+	Round         int32                  `protobuf:"varint,2,opt,name=round,proto3" json:"round,omitempty"`        // round of the run
+	Election      int32                  `protobuf:"varint,3,opt,name=election,proto3" json:"election,omitempty"`  // election of the round
+	View          int32                  `protobuf:"varint,4,opt,name=view,proto3" json:"view,omitempty"`          // view of the proposal
+	Digest        []byte                 `protobuf:"bytes,5,opt,name=digest,proto3" json:"digest,omitempty"`       // digest of the proposal
+	Signature     []byte                 `protobuf:"bytes,6,opt,name=signature,proto3" json:"signature,omitempty"` // ed25519 signature over (replica, round, election, view, digest)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignedPrepare) Reset() {
+	*x = SignedPrepare{}
+	mi := &file_vehicle_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignedPrepare) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignedPrepare) ProtoMessage() {}
+
+func (x *SignedPrepare) ProtoReflect() protoreflect.Message {
+	mi := &file_vehicle_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignedPrepare.ProtoReflect.Descriptor instead.
+func (*SignedPrepare) Descriptor() ([]byte, []int) {
+	return file_vehicle_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SignedPrepare) GetReplica() int32 {
+	if x != nil {
+		return x.Replica
+	}
+	return 0
+}
+
+func (x *SignedPrepare) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *SignedPrepare) GetElection() int32 {
+	if x != nil {
+		return x.Election
+	}
+	return 0
+}
+
+func (x *SignedPrepare) GetView() int32 {
+	if x != nil {
+		return x.View
+	}
+	return 0
+}
+
+func (x *SignedPrepare) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *SignedPrepare) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+// SignedCommit message definition: a BFT Commit signed with the replica's ed25519 key
+type SignedCommit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Replica       int32                  `protobuf:"varint,1,opt,name=replica,proto3" json:"replica,omitempty"`    // replica that commits the proposal
+	Round         int32                  `protobuf:"varint,2,opt,name=round,proto3" json:"round,omitempty"`        // round of the run
+	Election      int32                  `protobuf:"varint,3,opt,name=election,proto3" json:"election,omitempty"`  // election of the round
+	View          int32                  `protobuf:"varint,4,opt,name=view,proto3" json:"view,omitempty"`          // view of the proposal
+	Digest        []byte                 `protobuf:"bytes,5,opt,name=digest,proto3" json:"digest,omitempty"`       // digest of the proposal
+	Signature     []byte                 `protobuf:"bytes,6,opt,name=signature,proto3" json:"signature,omitempty"` // ed25519 signature over (replica, round, election, view, digest)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignedCommit) Reset() {
+	*x = SignedCommit{}
+	mi := &file_vehicle_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignedCommit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignedCommit) ProtoMessage() {}
+
+func (x *SignedCommit) ProtoReflect() protoreflect.Message {
+	mi := &file_vehicle_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignedCommit.ProtoReflect.Descriptor instead.
+func (*SignedCommit) Descriptor() ([]byte, []int) {
+	return file_vehicle_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SignedCommit) GetReplica() int32 {
+	if x != nil {
+		return x.Replica
+	}
+	return 0
+}
+
+func (x *SignedCommit) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *SignedCommit) GetElection() int32 {
+	if x != nil {
+		return x.Election
+	}
+	return 0
+}
+
+func (x *SignedCommit) GetView() int32 {
+	if x != nil {
+		return x.View
+	}
+	return 0
+}
+
+func (x *SignedCommit) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *SignedCommit) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+// SignedVote message definition: a ReceiveRequest vote signed with the voter's ed25519 key
+type SignedVote struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Voter         int32                  `protobuf:"varint,1,opt,name=voter,proto3" json:"voter,omitempty"`         // vehicle that granted the vote
+	Candidate     int32                  `protobuf:"varint,2,opt,name=candidate,proto3" json:"candidate,omitempty"` // vehicle the vote was granted to
+	Term          int32                  `protobuf:"varint,3,opt,name=term,proto3" json:"term,omitempty"`           // term of the vote
+	Signature     []byte                 `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`  // ed25519 signature over (voter, candidate, round, election, term)
+	Round         int32                  `protobuf:"varint,5,opt,name=round,proto3" json:"round,omitempty"`         // round of the run the vote was granted in
+	Election      int32                  `protobuf:"varint,6,opt,name=election,proto3" json:"election,omitempty"`   // election of the round the vote was granted in
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignedVote) Reset() {
+	*x = SignedVote{}
+	mi := &file_vehicle_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignedVote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignedVote) ProtoMessage() {}
+
+func (x *SignedVote) ProtoReflect() protoreflect.Message {
+	mi := &file_vehicle_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignedVote.ProtoReflect.Descriptor instead.
+func (*SignedVote) Descriptor() ([]byte, []int) {
+	return file_vehicle_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SignedVote) GetVoter() int32 {
+	if x != nil {
+		return x.Voter
+	}
+	return 0
+}
+
+func (x *SignedVote) GetCandidate() int32 {
+	if x != nil {
+		return x.Candidate
+	}
+	return 0
+}
+
+func (x *SignedVote) GetTerm() int32 {
+	if x != nil {
+		return x.Term
+	}
+	return 0
+}
+
+func (x *SignedVote) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *SignedVote) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *SignedVote) GetElection() int32 {
+	if x != nil {
+		return x.Election
+	}
+	return 0
+}
+
+// Schedule message definition: the passing order decided in one term
+type Schedule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Term          int32                  `protobuf:"varint,1,opt,name=term,proto3" json:"term,omitempty"`     // term in which the schedule was decided
+	Leader        int32                  `protobuf:"varint,2,opt,name=leader,proto3" json:"leader,omitempty"` // vehicle that announced the schedule
+	Groups        []*PassGroup           `protobuf:"bytes,3,rep,name=groups,proto3" json:"groups,omitempty"`  // groups in passing order
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Schedule) Reset() {
+	*x = Schedule{}
+	mi := &file_vehicle_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Schedule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Schedule) ProtoMessage() {}
+
+func (x *Schedule) ProtoReflect() protoreflect.Message {
+	mi := &file_vehicle_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Schedule.ProtoReflect.Descriptor instead.
+func (*Schedule) Descriptor() ([]byte, []int) {
+	return file_vehicle_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Schedule) GetTerm() int32 {
+	if x != nil {
+		return x.Term
+	}
+	return 0
+}
+
+func (x *Schedule) GetLeader() int32 {
+	if x != nil {
+		return x.Leader
+	}
+	return 0
+}
+
+func (x *Schedule) GetGroups() []*PassGroup {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+// PassGroup message definition: vehicles that cross the intersection together
+type PassGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Vehicles      []int32                `protobuf:"varint,1,rep,packed,name=vehicles,proto3" json:"vehicles,omitempty"` // vehicle numbers
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PassGroup) Reset() {
+	*x = PassGroup{}
+	mi := &file_vehicle_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PassGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PassGroup) ProtoMessage() {}
+
+func (x *PassGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_vehicle_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PassGroup.ProtoReflect.Descriptor instead.
+func (*PassGroup) Descriptor() ([]byte, []int) {
+	return file_vehicle_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PassGroup) GetVehicles() []int32 {
+	if x != nil {
+		return x.Vehicles
+	}
+	return nil
+}
+
 // Response message definition
 type Response struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
@@ -230,13 +772,20 @@ type Response struct {
 	DirectionStatus   string                 `protobuf:"bytes,3,opt,name=direction_status,json=directionStatus,proto3" json:"direction_status,omitempty"`       // True if directions are compatible
 	ResponseDirection string                 `protobuf:"bytes,4,opt,name=response_direction,json=responseDirection,proto3" json:"response_direction,omitempty"` // direction of the responding vehicle
 	Vehicle           *Vehicle               `protobuf:"bytes,5,opt,name=vehicle,proto3" json:"vehicle,omitempty"`                                              // vehicle information in response
+	Schedule          *Schedule              `protobuf:"bytes,6,opt,name=schedule,proto3" json:"schedule,omitempty"`                                            // BFT schedule the replica is locked on, if any
+	View              int32                  `protobuf:"varint,7,opt,name=view,proto3" json:"view,omitempty"`                                                   // BFT view the replica is in, or the view of its lock
+	Digest            []byte                 `protobuf:"bytes,8,opt,name=digest,proto3" json:"digest,omitempty"`                                                // BFT proposal the replica accepted
+	Vote              *SignedVote            `protobuf:"bytes,9,opt,name=vote,proto3" json:"vote,omitempty"`                                                    // signed vote granted by ReceiveRequest
+	Commit            *SignedCommit          `protobuf:"bytes,10,opt,name=commit,proto3" json:"commit,omitempty"`                                               // signed BFT Commit of the replica once it prepared the proposal
+	Commits           []*SignedCommit        `protobuf:"bytes,11,rep,name=commits,proto3" json:"commits,omitempty"`                                             // BFT commit certificate the replica decided on
+	Prepare           *SignedPrepare         `protobuf:"bytes,12,opt,name=prepare,proto3" json:"prepare,omitempty"`                                             // signed BFT Prepare of the replica once it accepted the proposal
 	unknownFields     protoimpl.UnknownFields
 	sizeCache         protoimpl.SizeCache
 }
 
 func (x *Response) Reset() {
 	*x = Response{}
-	mi := &file_vehicle_proto_msgTypes[2]
+	mi := &file_vehicle_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -248,7 +797,7 @@ func (x *Response) String() string {
 func (*Response) ProtoMessage() {}
 
 func (x *Response) ProtoReflect() protoreflect.Message {
-	mi := &file_vehicle_proto_msgTypes[2]
+	mi := &file_vehicle_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -261,7 +810,7 @@ func (x *Response) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Response.ProtoReflect.Descriptor instead.
 func (*Response) Descriptor() ([]byte, []int) {
-	return file_vehicle_proto_rawDescGZIP(), []int{2}
+	return file_vehicle_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *Response) GetMessage() string {
@@ -299,6 +848,55 @@ func (x *Response) GetVehicle() *Vehicle {
 	return nil
 }
 
+func (x *Response) GetSchedule() *Schedule {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+func (x *Response) GetView() int32 {
+	if x != nil {
+		return x.View
+	}
+	return 0
+}
+
+func (x *Response) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *Response) GetVote() *SignedVote {
+	if x != nil {
+		return x.Vote
+	}
+	return nil
+}
+
+func (x *Response) GetCommit() *SignedCommit {
+	if x != nil {
+		return x.Commit
+	}
+	return nil
+}
+
+func (x *Response) GetCommits() []*SignedCommit {
+	if x != nil {
+		return x.Commits
+	}
+	return nil
+}
+
+func (x *Response) GetPrepare() *SignedPrepare {
+	if x != nil {
+		return x.Prepare
+	}
+	return nil
+}
+
 // ConcurrentVehicle message definition
 type ConcurrentVehicle struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -310,7 +908,7 @@ type ConcurrentVehicle struct {
 
 func (x *ConcurrentVehicle) Reset() {
 	*x = ConcurrentVehicle{}
-	mi := &file_vehicle_proto_msgTypes[3]
+	mi := &file_vehicle_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -322,7 +920,7 @@ func (x *ConcurrentVehicle) String() string {
 func (*ConcurrentVehicle) ProtoMessage() {}
 
 func (x *ConcurrentVehicle) ProtoReflect() protoreflect.Message {
-	mi := &file_vehicle_proto_msgTypes[3]
+	mi := &file_vehicle_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -335,7 +933,7 @@ func (x *ConcurrentVehicle) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConcurrentVehicle.ProtoReflect.Descriptor instead.
 func (*ConcurrentVehicle) Descriptor() ([]byte, []int) {
-	return file_vehicle_proto_rawDescGZIP(), []int{3}
+	return file_vehicle_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *ConcurrentVehicle) GetVehicle() *Vehicle {
@@ -366,7 +964,7 @@ type VehicleRPC struct {
 
 func (x *VehicleRPC) Reset() {
 	*x = VehicleRPC{}
-	mi := &file_vehicle_proto_msgTypes[4]
+	mi := &file_vehicle_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -378,7 +976,7 @@ func (x *VehicleRPC) String() string {
 func (*VehicleRPC) ProtoMessage() {}
 
 func (x *VehicleRPC) ProtoReflect() protoreflect.Message {
-	mi := &file_vehicle_proto_msgTypes[4]
+	mi := &file_vehicle_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -391,7 +989,7 @@ func (x *VehicleRPC) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VehicleRPC.ProtoReflect.Descriptor instead.
 func (*VehicleRPC) Descriptor() ([]byte, []int) {
-	return file_vehicle_proto_rawDescGZIP(), []int{4}
+	return file_vehicle_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *VehicleRPC) GetAddress() int32 {
@@ -433,7 +1031,7 @@ var File_vehicle_proto protoreflect.FileDescriptor
 
 const file_vehicle_proto_rawDesc = "" +
 	"\n" +
-	"\rvehicle.proto\x12\rvehicleServer\x1a\x1fgoogle/protobuf/timestamp.proto\"\xac\x03\n" +
+	"\rvehicle.proto\x12\rvehicleServer\x1a\x1fgoogle/protobuf/timestamp.proto\"\x9f\x04\n" +
 	"\aVehicle\x12\x16\n" +
 	"\x06number\x18\x01 \x01(\x05R\x06number\x12\x1c\n" +
 	"\tdirection\x18\x02 \x01(\tR\tdirection\x12\x18\n" +
@@ -443,22 +1041,81 @@ const file_vehicle_proto_rawDesc = "" +
 	"\rreceive_votes\x18\x05 \x01(\x05R\freceiveVotes\x124\n" +
 	"\tcovehicle\x18\x06 \x03(\v2\x16.vehicleServer.VehicleR\tcovehicle\x12#\n" +
 	"\rrandom_number\x18\a \x01(\x05R\frandomNumber\x12#\n" +
-	"\rlicense_plate\x18\b \x01(\x05R\flicensePlate\x12?\n" +
+	"\rlicense_plate\x18\b \x01(\tR\flicensePlate\x12?\n" +
 	"\relection_time\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\felectionTime\x12#\n" +
 	"\relection_vote\x18\n" +
 	" \x01(\x05R\felectionVote\x12'\n" +
-	"\x0felection_status\x18\v \x01(\tR\x0eelectionStatus\"\x9a\x01\n" +
+	"\x0felection_status\x18\v \x01(\tR\x0eelectionStatus\x12\x12\n" +
+	"\x04term\x18\f \x01(\x05R\x04term\x12\x16\n" +
+	"\x06leader\x18\r \x01(\x05R\x06leader\x12\x18\n" +
+	"\alamport\x18\x0e \x01(\x03R\alamport\x12+\n" +
+	"\x03hlc\x18\x0f \x01(\v2\x19.vehicleServer.HybridTimeR\x03hlc\":\n" +
+	"\n" +
+	"HybridTime\x12\x12\n" +
+	"\x04wall\x18\x01 \x01(\x03R\x04wall\x12\x18\n" +
+	"\alogical\x18\x02 \x01(\x05R\alogical\"\xd6\x04\n" +
 	"\aRequest\x120\n" +
 	"\avehicle\x18\x01 \x01(\v2\x16.vehicleServer.VehicleR\avehicle\x12\x12\n" +
 	"\x04Port\x18\x02 \x01(\tR\x04Port\x12%\n" +
 	"\x0etotal_vehicles\x18\x03 \x01(\x05R\rtotalVehicles\x12\"\n" +
-	"\fRandomNumber\x18\x04 \x01(\x05R\fRandomNumber\"\xc8\x01\n" +
+	"\fRandomNumber\x18\x04 \x01(\x05R\fRandomNumber\x12\x1e\n" +
+	"\n" +
+	"commitment\x18\x05 \x01(\fR\n" +
+	"commitment\x12\x14\n" +
+	"\x05phase\x18\x06 \x01(\tR\x05phase\x12\x12\n" +
+	"\x04term\x18\a \x01(\x05R\x04term\x123\n" +
+	"\bschedule\x18\b \x01(\v2\x17.vehicleServer.ScheduleR\bschedule\x12\x12\n" +
+	"\x04view\x18\t \x01(\x05R\x04view\x12\x16\n" +
+	"\x06digest\x18\n" +
+	" \x01(\fR\x06digest\x12\x16\n" +
+	"\x06roster\x18\v \x03(\x05R\x06roster\x12\x16\n" +
+	"\x06sender\x18\f \x01(\x05R\x06sender\x12;\n" +
+	"\vcertificate\x18\r \x03(\v2\x19.vehicleServer.SignedVoteR\vcertificate\x123\n" +
+	"\x06commit\x18\x0e \x01(\v2\x1b.vehicleServer.SignedCommitR\x06commit\x125\n" +
+	"\acommits\x18\x0f \x03(\v2\x1b.vehicleServer.SignedCommitR\acommits\x126\n" +
+	"\aprepare\x18\x10 \x01(\v2\x1c.vehicleServer.SignedPrepareR\aprepare\"\xa5\x01\n" +
+	"\rSignedPrepare\x12\x18\n" +
+	"\areplica\x18\x01 \x01(\x05R\areplica\x12\x14\n" +
+	"\x05round\x18\x02 \x01(\x05R\x05round\x12\x1a\n" +
+	"\belection\x18\x03 \x01(\x05R\belection\x12\x12\n" +
+	"\x04view\x18\x04 \x01(\x05R\x04view\x12\x16\n" +
+	"\x06digest\x18\x05 \x01(\fR\x06digest\x12\x1c\n" +
+	"\tsignature\x18\x06 \x01(\fR\tsignature\"\xa4\x01\n" +
+	"\fSignedCommit\x12\x18\n" +
+	"\areplica\x18\x01 \x01(\x05R\areplica\x12\x14\n" +
+	"\x05round\x18\x02 \x01(\x05R\x05round\x12\x1a\n" +
+	"\belection\x18\x03 \x01(\x05R\belection\x12\x12\n" +
+	"\x04view\x18\x04 \x01(\x05R\x04view\x12\x16\n" +
+	"\x06digest\x18\x05 \x01(\fR\x06digest\x12\x1c\n" +
+	"\tsignature\x18\x06 \x01(\fR\tsignature\"\xa4\x01\n" +
+	"\n" +
+	"SignedVote\x12\x14\n" +
+	"\x05voter\x18\x01 \x01(\x05R\x05voter\x12\x1c\n" +
+	"\tcandidate\x18\x02 \x01(\x05R\tcandidate\x12\x12\n" +
+	"\x04term\x18\x03 \x01(\x05R\x04term\x12\x1c\n" +
+	"\tsignature\x18\x04 \x01(\fR\tsignature\x12\x14\n" +
+	"\x05round\x18\x05 \x01(\x05R\x05round\x12\x1a\n" +
+	"\belection\x18\x06 \x01(\x05R\belection\"h\n" +
+	"\bSchedule\x12\x12\n" +
+	"\x04term\x18\x01 \x01(\x05R\x04term\x12\x16\n" +
+	"\x06leader\x18\x02 \x01(\x05R\x06leader\x120\n" +
+	"\x06groups\x18\x03 \x03(\v2\x18.vehicleServer.PassGroupR\x06groups\"'\n" +
+	"\tPassGroup\x12\x1a\n" +
+	"\bvehicles\x18\x01 \x03(\x05R\bvehicles\"\xfc\x03\n" +
 	"\bResponse\x12\x18\n" +
 	"\amessage\x18\x01 \x01(\tR\amessage\x12\x16\n" +
 	"\x06status\x18\x02 \x01(\tR\x06status\x12)\n" +
 	"\x10direction_status\x18\x03 \x01(\tR\x0fdirectionStatus\x12-\n" +
 	"\x12response_direction\x18\x04 \x01(\tR\x11responseDirection\x120\n" +
-	"\avehicle\x18\x05 \x01(\v2\x16.vehicleServer.VehicleR\avehicle\"{\n" +
+	"\avehicle\x18\x05 \x01(\v2\x16.vehicleServer.VehicleR\avehicle\x123\n" +
+	"\bschedule\x18\x06 \x01(\v2\x17.vehicleServer.ScheduleR\bschedule\x12\x12\n" +
+	"\x04view\x18\a \x01(\x05R\x04view\x12\x16\n" +
+	"\x06digest\x18\b \x01(\fR\x06digest\x12-\n" +
+	"\x04vote\x18\t \x01(\v2\x19.vehicleServer.SignedVoteR\x04vote\x123\n" +
+	"\x06commit\x18\n" +
+	" \x01(\v2\x1b.vehicleServer.SignedCommitR\x06commit\x125\n" +
+	"\acommits\x18\v \x03(\v2\x1b.vehicleServer.SignedCommitR\acommits\x126\n" +
+	"\aprepare\x18\f \x01(\v2\x1c.vehicleServer.SignedPrepareR\aprepare\"{\n" +
 	"\x11ConcurrentVehicle\x120\n" +
 	"\avehicle\x18\x01 \x01(\v2\x16.vehicleServer.VehicleR\avehicle\x124\n" +
 	"\x04next\x18\x02 \x01(\v2 .vehicleServer.ConcurrentVehicleR\x04next\"\xe0\x02\n" +
@@ -472,12 +1129,22 @@ const file_vehicle_proto_rawDesc = "" +
 	"\x17concurrent_vehicle_list\x18\x05 \x01(\v2 .vehicleServer.ConcurrentVehicleR\x15concurrentVehicleList\x1aS\n" +
 	"\rVehiclesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\x05R\x03key\x12,\n" +
-	"\x05value\x18\x02 \x01(\v2\x16.vehicleServer.VehicleR\x05value:\x028\x012\x9e\x02\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.vehicleServer.VehicleR\x05value:\x028\x012\x9c\x06\n" +
 	"\x0eVehicleService\x12A\n" +
 	"\x0eReceiveRequest\x12\x16.vehicleServer.Request\x1a\x17.vehicleServer.Response\x12B\n" +
 	"\x0fRandomAgreement\x12\x16.vehicleServer.Request\x1a\x17.vehicleServer.Response\x12A\n" +
 	"\x0eLeaderElection\x12\x16.vehicleServer.Request\x1a\x17.vehicleServer.Response\x12B\n" +
-	"\x0fUpdateVoteCount\x12\x16.vehicleServer.Request\x1a\x17.vehicleServer.ResponseB\x11Z\x0f.;vehicleServerb\x06proto3"
+	"\x0fUpdateVoteCount\x12\x16.vehicleServer.Request\x1a\x17.vehicleServer.Response\x12A\n" +
+	"\x0eCommitSchedule\x12\x16.vehicleServer.Request\x1a\x17.vehicleServer.Response\x12;\n" +
+	"\bGetState\x12\x16.vehicleServer.Request\x1a\x17.vehicleServer.Response\x12<\n" +
+	"\tBftRoster\x12\x16.vehicleServer.Request\x1a\x17.vehicleServer.Response\x12@\n" +
+	"\rBftViewChange\x12\x16.vehicleServer.Request\x1a\x17.vehicleServer.Response\x12@\n" +
+	"\rBftPrePrepare\x12\x16.vehicleServer.Request\x1a\x17.vehicleServer.Response\x12=\n" +
+	"\n" +
+	"BftPrepare\x12\x16.vehicleServer.Request\x1a\x17.vehicleServer.Response\x12<\n" +
+	"\tBftCommit\x12\x16.vehicleServer.Request\x1a\x17.vehicleServer.Response\x12=\n" +
+	"\n" +
+	"BftDecided\x12\x16.vehicleServer.Request\x1a\x17.vehicleServer.ResponseB\x11Z\x0f.;vehicleServerb\x06proto3"
 
 var (
 	file_vehicle_proto_rawDescOnce sync.Once
@@ -491,39 +1158,73 @@ func file_vehicle_proto_rawDescGZIP() []byte {
 	return file_vehicle_proto_rawDescData
 }
 
-var file_vehicle_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_vehicle_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_vehicle_proto_goTypes = []any{
 	(*Vehicle)(nil),               // 0: vehicleServer.Vehicle
-	(*Request)(nil),               // 1: vehicleServer.Request
-	(*Response)(nil),              // 2: vehicleServer.Response
-	(*ConcurrentVehicle)(nil),     // 3: vehicleServer.ConcurrentVehicle
-	(*VehicleRPC)(nil),            // 4: vehicleServer.VehicleRPC
-	nil,                           // 5: vehicleServer.VehicleRPC.VehiclesEntry
-	(*timestamppb.Timestamp)(nil), // 6: google.protobuf.Timestamp
+	(*HybridTime)(nil),            // 1: vehicleServer.HybridTime
+	(*Request)(nil),               // 2: vehicleServer.Request
+	(*SignedPrepare)(nil),         // 3: vehicleServer.SignedPrepare
+	(*SignedCommit)(nil),          // 4: vehicleServer.SignedCommit
+	(*SignedVote)(nil),            // 5: vehicleServer.SignedVote
+	(*Schedule)(nil),              // 6: vehicleServer.Schedule
+	(*PassGroup)(nil),             // 7: vehicleServer.PassGroup
+	(*Response)(nil),              // 8: vehicleServer.Response
+	(*ConcurrentVehicle)(nil),     // 9: vehicleServer.ConcurrentVehicle
+	(*VehicleRPC)(nil),            // 10: vehicleServer.VehicleRPC
+	nil,                           // 11: vehicleServer.VehicleRPC.VehiclesEntry
+	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
 }
 var file_vehicle_proto_depIdxs = []int32{
 	0,  // 0: vehicleServer.Vehicle.covehicle:type_name -> vehicleServer.Vehicle
-	6,  // 1: vehicleServer.Vehicle.election_time:type_name -> google.protobuf.Timestamp
-	0,  // 2: vehicleServer.Request.vehicle:type_name -> vehicleServer.Vehicle
-	0,  // 3: vehicleServer.Response.vehicle:type_name -> vehicleServer.Vehicle
-	0,  // 4: vehicleServer.ConcurrentVehicle.vehicle:type_name -> vehicleServer.Vehicle
-	3,  // 5: vehicleServer.ConcurrentVehicle.next:type_name -> vehicleServer.ConcurrentVehicle
-	5,  // 6: vehicleServer.VehicleRPC.vehicles:type_name -> vehicleServer.VehicleRPC.VehiclesEntry
-	3,  // 7: vehicleServer.VehicleRPC.concurrent_vehicle_list:type_name -> vehicleServer.ConcurrentVehicle
-	0,  // 8: vehicleServer.VehicleRPC.VehiclesEntry.value:type_name -> vehicleServer.Vehicle
-	1,  // 9: vehicleServer.VehicleService.ReceiveRequest:input_type -> vehicleServer.Request
-	1,  // 10: vehicleServer.VehicleService.RandomAgreement:input_type -> vehicleServer.Request
-	1,  // 11: vehicleServer.VehicleService.LeaderElection:input_type -> vehicleServer.Request
-	1,  // 12: vehicleServer.VehicleService.UpdateVoteCount:input_type -> vehicleServer.Request
-	2,  // 13: vehicleServer.VehicleService.ReceiveRequest:output_type -> vehicleServer.Response
-	2,  // 14: vehicleServer.VehicleService.RandomAgreement:output_type -> vehicleServer.Response
-	2,  // 15: vehicleServer.VehicleService.LeaderElection:output_type -> vehicleServer.Response
-	2,  // 16: vehicleServer.VehicleService.UpdateVoteCount:output_type -> vehicleServer.Response
-	13, // [13:17] is the sub-list for method output_type
-	9,  // [9:13] is the sub-list for method input_type
-	9,  // [9:9] is the sub-list for extension type_name
-	9,  // [9:9] is the sub-list for extension extendee
-	0,  // [0:9] is the sub-list for field type_name
+	12, // 1: vehicleServer.Vehicle.election_time:type_name -> google.protobuf.Timestamp
+	1,  // 2: vehicleServer.Vehicle.hlc:type_name -> vehicleServer.HybridTime
+	0,  // 3: vehicleServer.Request.vehicle:type_name -> vehicleServer.Vehicle
+	6,  // 4: vehicleServer.Request.schedule:type_name -> vehicleServer.Schedule
+	5,  // 5: vehicleServer.Request.certificate:type_name -> vehicleServer.SignedVote
+	4,  // 6: vehicleServer.Request.commit:type_name -> vehicleServer.SignedCommit
+	4,  // 7: vehicleServer.Request.commits:type_name -> vehicleServer.SignedCommit
+	3,  // 8: vehicleServer.Request.prepare:type_name -> vehicleServer.SignedPrepare
+	7,  // 9: vehicleServer.Schedule.groups:type_name -> vehicleServer.PassGroup
+	0,  // 10: vehicleServer.Response.vehicle:type_name -> vehicleServer.Vehicle
+	6,  // 11: vehicleServer.Response.schedule:type_name -> vehicleServer.Schedule
+	5,  // 12: vehicleServer.Response.vote:type_name -> vehicleServer.SignedVote
+	4,  // 13: vehicleServer.Response.commit:type_name -> vehicleServer.SignedCommit
+	4,  // 14: vehicleServer.Response.commits:type_name -> vehicleServer.SignedCommit
+	3,  // 15: vehicleServer.Response.prepare:type_name -> vehicleServer.SignedPrepare
+	0,  // 16: vehicleServer.ConcurrentVehicle.vehicle:type_name -> vehicleServer.Vehicle
+	9,  // 17: vehicleServer.ConcurrentVehicle.next:type_name -> vehicleServer.ConcurrentVehicle
+	11, // 18: vehicleServer.VehicleRPC.vehicles:type_name -> vehicleServer.VehicleRPC.VehiclesEntry
+	9,  // 19: vehicleServer.VehicleRPC.concurrent_vehicle_list:type_name -> vehicleServer.ConcurrentVehicle
+	0,  // 20: vehicleServer.VehicleRPC.VehiclesEntry.value:type_name -> vehicleServer.Vehicle
+	2,  // 21: vehicleServer.VehicleService.ReceiveRequest:input_type -> vehicleServer.Request
+	2,  // 22: vehicleServer.VehicleService.RandomAgreement:input_type -> vehicleServer.Request
+	2,  // 23: vehicleServer.VehicleService.LeaderElection:input_type -> vehicleServer.Request
+	2,  // 24: vehicleServer.VehicleService.UpdateVoteCount:input_type -> vehicleServer.Request
+	2,  // 25: vehicleServer.VehicleService.CommitSchedule:input_type -> vehicleServer.Request
+	2,  // 26: vehicleServer.VehicleService.GetState:input_type -> vehicleServer.Request
+	2,  // 27: vehicleServer.VehicleService.BftRoster:input_type -> vehicleServer.Request
+	2,  // 28: vehicleServer.VehicleService.BftViewChange:input_type -> vehicleServer.Request
+	2,  // 29: vehicleServer.VehicleService.BftPrePrepare:input_type -> vehicleServer.Request
+	2,  // 30: vehicleServer.VehicleService.BftPrepare:input_type -> vehicleServer.Request
+	2,  // 31: vehicleServer.VehicleService.BftCommit:input_type -> vehicleServer.Request
+	2,  // 32: vehicleServer.VehicleService.BftDecided:input_type -> vehicleServer.Request
+	8,  // 33: vehicleServer.VehicleService.ReceiveRequest:output_type -> vehicleServer.Response
+	8,  // 34: vehicleServer.VehicleService.RandomAgreement:output_type -> vehicleServer.Response
+	8,  // 35: vehicleServer.VehicleService.LeaderElection:output_type -> vehicleServer.Response
+	8,  // 36: vehicleServer.VehicleService.UpdateVoteCount:output_type -> vehicleServer.Response
+	8,  // 37: vehicleServer.VehicleService.CommitSchedule:output_type -> vehicleServer.Response
+	8,  // 38: vehicleServer.VehicleService.GetState:output_type -> vehicleServer.Response
+	8,  // 39: vehicleServer.VehicleService.BftRoster:output_type -> vehicleServer.Response
+	8,  // 40: vehicleServer.VehicleService.BftViewChange:output_type -> vehicleServer.Response
+	8,  // 41: vehicleServer.VehicleService.BftPrePrepare:output_type -> vehicleServer.Response
+	8,  // 42: vehicleServer.VehicleService.BftPrepare:output_type -> vehicleServer.Response
+	8,  // 43: vehicleServer.VehicleService.BftCommit:output_type -> vehicleServer.Response
+	8,  // 44: vehicleServer.VehicleService.BftDecided:output_type -> vehicleServer.Response
+	33, // [33:45] is the sub-list for method output_type
+	21, // [21:33] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
 }
 
 func init() { file_vehicle_proto_init() }
@@ -537,7 +1238,7 @@ func file_vehicle_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_vehicle_proto_rawDesc), len(file_vehicle_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
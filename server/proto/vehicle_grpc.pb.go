@@ -29,6 +29,14 @@ const (
 	VehicleService_RandomAgreement_FullMethodName = "/vehicleServer.VehicleService/RandomAgreement"
 	VehicleService_LeaderElection_FullMethodName  = "/vehicleServer.VehicleService/LeaderElection"
 	VehicleService_UpdateVoteCount_FullMethodName = "/vehicleServer.VehicleService/UpdateVoteCount"
+	VehicleService_CommitSchedule_FullMethodName  = "/vehicleServer.VehicleService/CommitSchedule"
+	VehicleService_GetState_FullMethodName        = "/vehicleServer.VehicleService/GetState"
+	VehicleService_BftRoster_FullMethodName       = "/vehicleServer.VehicleService/BftRoster"
+	VehicleService_BftViewChange_FullMethodName   = "/vehicleServer.VehicleService/BftViewChange"
+	VehicleService_BftPrePrepare_FullMethodName   = "/vehicleServer.VehicleService/BftPrePrepare"
+	VehicleService_BftPrepare_FullMethodName      = "/vehicleServer.VehicleService/BftPrepare"
+	VehicleService_BftCommit_FullMethodName       = "/vehicleServer.VehicleService/BftCommit"
+	VehicleService_BftDecided_FullMethodName      = "/vehicleServer.VehicleService/BftDecided"
 )
 
 // VehicleServiceClient is the client API for VehicleService service.
@@ -39,6 +47,14 @@ type VehicleServiceClient interface {
 	RandomAgreement(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
 	LeaderElection(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
 	UpdateVoteCount(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	CommitSchedule(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	GetState(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	BftRoster(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	BftViewChange(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	BftPrePrepare(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	BftPrepare(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	BftCommit(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	BftDecided(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
 }
 
 type vehicleServiceClient struct {
@@ -89,6 +105,86 @@ func (c *vehicleServiceClient) UpdateVoteCount(ctx context.Context, in *Request,
 	return out, nil
 }
 
+func (c *vehicleServiceClient) CommitSchedule(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, VehicleService_CommitSchedule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vehicleServiceClient) GetState(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, VehicleService_GetState_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vehicleServiceClient) BftRoster(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, VehicleService_BftRoster_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vehicleServiceClient) BftViewChange(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, VehicleService_BftViewChange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vehicleServiceClient) BftPrePrepare(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, VehicleService_BftPrePrepare_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vehicleServiceClient) BftPrepare(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, VehicleService_BftPrepare_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vehicleServiceClient) BftCommit(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, VehicleService_BftCommit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vehicleServiceClient) BftDecided(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, VehicleService_BftDecided_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // VehicleServiceServer is the server API for VehicleService service.
 // All implementations must embed UnimplementedVehicleServiceServer
 // for forward compatibility.
@@ -97,6 +193,14 @@ type VehicleServiceServer interface {
 	RandomAgreement(context.Context, *Request) (*Response, error)
 	LeaderElection(context.Context, *Request) (*Response, error)
 	UpdateVoteCount(context.Context, *Request) (*Response, error)
+	CommitSchedule(context.Context, *Request) (*Response, error)
+	GetState(context.Context, *Request) (*Response, error)
+	BftRoster(context.Context, *Request) (*Response, error)
+	BftViewChange(context.Context, *Request) (*Response, error)
+	BftPrePrepare(context.Context, *Request) (*Response, error)
+	BftPrepare(context.Context, *Request) (*Response, error)
+	BftCommit(context.Context, *Request) (*Response, error)
+	BftDecided(context.Context, *Request) (*Response, error)
 	mustEmbedUnimplementedVehicleServiceServer()
 }
 
@@ -119,6 +223,30 @@ func (UnimplementedVehicleServiceServer) LeaderElection(context.Context, *Reques
 func (UnimplementedVehicleServiceServer) UpdateVoteCount(context.Context, *Request) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateVoteCount not implemented")
 }
+func (UnimplementedVehicleServiceServer) CommitSchedule(context.Context, *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CommitSchedule not implemented")
+}
+func (UnimplementedVehicleServiceServer) GetState(context.Context, *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetState not implemented")
+}
+func (UnimplementedVehicleServiceServer) BftRoster(context.Context, *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BftRoster not implemented")
+}
+func (UnimplementedVehicleServiceServer) BftViewChange(context.Context, *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BftViewChange not implemented")
+}
+func (UnimplementedVehicleServiceServer) BftPrePrepare(context.Context, *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BftPrePrepare not implemented")
+}
+func (UnimplementedVehicleServiceServer) BftPrepare(context.Context, *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BftPrepare not implemented")
+}
+func (UnimplementedVehicleServiceServer) BftCommit(context.Context, *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BftCommit not implemented")
+}
+func (UnimplementedVehicleServiceServer) BftDecided(context.Context, *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BftDecided not implemented")
+}
 func (UnimplementedVehicleServiceServer) mustEmbedUnimplementedVehicleServiceServer() {}
 func (UnimplementedVehicleServiceServer) testEmbeddedByValue()                        {}
 
@@ -212,6 +340,150 @@ func _VehicleService_UpdateVoteCount_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _VehicleService_CommitSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VehicleServiceServer).CommitSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VehicleService_CommitSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VehicleServiceServer).CommitSchedule(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VehicleService_GetState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VehicleServiceServer).GetState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VehicleService_GetState_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VehicleServiceServer).GetState(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VehicleService_BftRoster_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VehicleServiceServer).BftRoster(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VehicleService_BftRoster_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VehicleServiceServer).BftRoster(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VehicleService_BftViewChange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VehicleServiceServer).BftViewChange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VehicleService_BftViewChange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VehicleServiceServer).BftViewChange(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VehicleService_BftPrePrepare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VehicleServiceServer).BftPrePrepare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VehicleService_BftPrePrepare_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VehicleServiceServer).BftPrePrepare(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VehicleService_BftPrepare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VehicleServiceServer).BftPrepare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VehicleService_BftPrepare_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VehicleServiceServer).BftPrepare(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VehicleService_BftCommit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VehicleServiceServer).BftCommit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VehicleService_BftCommit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VehicleServiceServer).BftCommit(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VehicleService_BftDecided_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VehicleServiceServer).BftDecided(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VehicleService_BftDecided_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VehicleServiceServer).BftDecided(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // VehicleService_ServiceDesc is the grpc.ServiceDesc for VehicleService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -235,6 +507,38 @@ var VehicleService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateVoteCount",
 			Handler:    _VehicleService_UpdateVoteCount_Handler,
 		},
+		{
+			MethodName: "CommitSchedule",
+			Handler:    _VehicleService_CommitSchedule_Handler,
+		},
+		{
+			MethodName: "GetState",
+			Handler:    _VehicleService_GetState_Handler,
+		},
+		{
+			MethodName: "BftRoster",
+			Handler:    _VehicleService_BftRoster_Handler,
+		},
+		{
+			MethodName: "BftViewChange",
+			Handler:    _VehicleService_BftViewChange_Handler,
+		},
+		{
+			MethodName: "BftPrePrepare",
+			Handler:    _VehicleService_BftPrePrepare_Handler,
+		},
+		{
+			MethodName: "BftPrepare",
+			Handler:    _VehicleService_BftPrepare_Handler,
+		},
+		{
+			MethodName: "BftCommit",
+			Handler:    _VehicleService_BftCommit_Handler,
+		},
+		{
+			MethodName: "BftDecided",
+			Handler:    _VehicleService_BftDecided_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "vehicle.proto",
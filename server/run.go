@@ -0,0 +1,81 @@
+package server
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	clock "main/clock"
+	config "main/config"
+	identity "main/identity"
+	quorum "main/quorum"
+	transport "main/transport"
+
+	"google.golang.org/grpc"
+)
+
+// Run is what the vehicle servers of one simulation run share: the clock and network of the run, the settings
+// the client chose for it, the local clocks of the vehicles and the counters the client reads after every round.
+// Every server of a run points to the same Run, so two runs in one process do not see each other's state.
+type Run struct {
+	Clock      clock.Clock         // time source used to stamp vehicle state
+	Transport  transport.Transport // network the vehicle servers listen on
+	TieBreak   string              // tie-break policy for candidates with equal ReceiveVotes in LeaderElection
+	Quorum     quorum.Policy       // quorum policy of the elections; a committed schedule needs a certified quorum of it
+	StateDir   string              // directory of the durable vote state; empty keeps the state in memory only
+	CA         *identity.Authority // local CA when the vehicles talk over mutual TLS; nil means plaintext
+	Keys       *identity.Ring      // key ring when votes and BFT Commit are signed; nil means plain votes
+	Crash      *CrashPlan          // crash faults; nil means the servers never crash
+	Strategies map[int32]string    // strategy of every malicious vehicle; a vehicle without an entry is honest
+
+	staleRejections     atomic.Int64 // requests rejected for carrying a stale term
+	randomTieBreaks     atomic.Int64 // LeaderElection ties decided by the RandomAgreement draw
+	invalidCertificates atomic.Int64 // claims rejected for an invalid quorum or commit certificate or an unsigned Prepare
+	spoofed             atomic.Int64 // requests that claimed another vehicle than their TLS peer
+
+	// Votes granted in ReceiveRequest, kept outside the vehicle servers so that they survive a restart: the
+	// candidate each vehicle voted for per term, and how many votes went to a second one.
+	ballots struct {
+		mu     sync.Mutex
+		votes  map[[2]int32]int32
+		double int
+	}
+
+	// Local clocks of the vehicles: the skew of every vehicle from the run clock and its Lamport and hybrid
+	// logical clocks. The client side and the server of a vehicle share them.
+	clocks struct {
+		mu       sync.Mutex
+		skews    map[int32]time.Duration
+		lamports map[int32]*clock.Lamport
+		hlcs     map[int32]*clock.HLC
+	}
+
+	// Ties decided since the last TakeTies.
+	ties struct {
+		mu      sync.Mutex
+		decided []Tie
+	}
+
+	// Crashed servers of the current election.
+	crashes struct {
+		mu         sync.Mutex
+		generation int            // bumped by StopRecovered so late restarts of an old election are dropped
+		crashed    map[int32]bool // vehicles that already crashed in this election; each crashes at most once
+		down       map[int32]bool // vehicles whose server is stopped right now
+		recovered  []*grpc.Server // restarted servers, stopped by StopRecovered
+		count      int
+		restarts   int
+	}
+}
+
+// Function name: NewRun
+// Returns a run on the real clock and TCP ports with the "time" tie-break and the majority quorum; the client
+// replaces what its scenario sets.
+func NewRun() *Run {
+	return &Run{
+		Clock:     clock.Real{},
+		Transport: transport.TCP{BasePort: config.BasePort},
+		TieBreak:  "time",
+		Quorum:    quorum.Majority{},
+	}
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"testing"
+
+	pb "main/client/proto"
+)
+
+func TestRunsDoNotShareState(t *testing.T) {
+	a, b := NewRun(), NewRun()
+
+	s := &server{Vehicle: &pb.Vehicle{Number: 1, Term: 2}, run: a}
+	s.checkTerm(&pb.Request{Term: 1})
+	a.recordVote(1, 1, 2)
+	a.recordVote(1, 1, 3)
+	a.recordTie(2, 3)
+	first := &pb.Vehicle{}
+	a.Stamp(1, first)
+	a.Stamp(1, first)
+
+	tests := []struct {
+		name string
+		a    int
+		b    int
+	}{
+		{"stale rejections", a.TakeStaleRejections(), b.TakeStaleRejections()},
+		{"double votes", a.TakeDoubleVotes(), b.TakeDoubleVotes()},
+		{"ties", len(a.TakeTies()), len(b.TakeTies())},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.a != 1 || tt.b != 0 {
+				t.Fatalf("got %d in the run and %d in the other run, want 1 and 0", tt.a, tt.b)
+			}
+		})
+	}
+
+	other := &pb.Vehicle{}
+	b.Stamp(1, other)
+	if first.Lamport != 2 || other.Lamport != 1 {
+		t.Fatalf("Lamport stamps = %d and %d, want 2 and 1: the runs share the clocks of vehicle 1", first.Lamport, other.Lamport)
+	}
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	pb "main/client/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Function name: CommitSchedule
+// Stores the passing schedule announced by the elected leader and acknowledges it.
+// A vehicle holds at most one schedule per term: a different schedule for a term that is already decided is ignored,
+// and a schedule from an older term is rejected by the term check. A schedule that does not come from its leader,
+// or whose leader cannot prove its election with signed votes, is rejected.
+func (s *server) CommitSchedule(ctx context.Context, req *pb.Request) (*pb.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req == nil || req.Schedule == nil {
+		return nil, fmt.Errorf("received nil schedule")
+	}
+
+	// checked before the term, so that a forged schedule of a later term cannot make this vehicle step down
+	if rejected := s.checkLeader(req); rejected != nil {
+		return rejected, nil
+	}
+	if rejected := s.checkElected(req); rejected != nil {
+		return rejected, nil
+	}
+	if rejected := s.checkTerm(req); rejected != nil {
+		return rejected, nil
+	}
+
+	if s.schedule != nil && s.schedule.Term == req.Schedule.Term && !proto.Equal(s.schedule, req.Schedule) {
+		return &pb.Response{
+			Message: fmt.Sprintf("Vehicle %d: term %d is already decided by leader %d", s.Vehicle.Number, s.schedule.Term, s.schedule.Leader),
+			Status:  "ignored",
+			Vehicle: proto.Clone(s.Vehicle).(*pb.Vehicle),
+		}, nil
+	}
+
+	s.schedule = proto.Clone(req.Schedule).(*pb.Schedule)
+	if s.Vehicle.Number == req.Schedule.Leader {
+		s.Vehicle.ElectionStatus = "Leader"
+	} else {
+		s.Vehicle.ElectionStatus = "Follower"
+	}
+	// every vehicle adopts the leader's vote count and ElectionTime, like a follower in LeaderElection
+	s.Vehicle.Leader = req.Schedule.Leader
+	if req.Vehicle != nil && req.Vehicle.Number == req.Schedule.Leader {
+		s.Vehicle.ReceiveVotes = req.Vehicle.ReceiveVotes
+		adoptStamp(s.Vehicle, req.Vehicle)
+	}
+	if err := s.persist(); err != nil {
+		return nil, err
+	}
+	if err := s.crashPoint("commit"); err != nil {
+		return nil, err
+	}
+
+	return &pb.Response{
+		Message: fmt.Sprintf("Vehicle %d: schedule of leader %d committed", s.Vehicle.Number, req.Schedule.Leader),
+		Status:  "acknowledged",
+		Vehicle: proto.Clone(s.Vehicle).(*pb.Vehicle),
+	}, nil
+}
+
+// Function name: checkLeader
+// Rejects a schedule that is not announced by its own leader for the term of the request. Must be called with s.mu held.
+func (s *server) checkLeader(req *pb.Request) *pb.Response {
+	if req.Vehicle.GetNumber() == req.Schedule.Leader && req.Schedule.Term == req.Term {
+		return nil
+	}
+	return &pb.Response{
+		Message: fmt.Sprintf("Vehicle %d announced the schedule of leader %d for term %d in term %d", req.Vehicle.GetNumber(), req.Schedule.Leader, req.Schedule.Term, req.Term),
+		Status:  "rejected",
+		Vehicle: proto.Clone(s.Vehicle).(*pb.Vehicle),
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	pb "main/client/proto"
+	identity "main/identity"
+	quorum "main/quorum"
+)
+
+func TestCommitScheduleLeader(t *testing.T) {
+	signed := &Run{Keys: identity.NewRing(), Quorum: quorum.Majority{}}
+	plain := &Run{Quorum: quorum.Majority{}}
+	roster := quorum.Round{Vehicles: []int32{1, 2, 3, 4}}
+	vote := func(voter int32, candidate int32, term int32) *pb.SignedVote {
+		s := &server{Vehicle: &pb.Vehicle{Number: voter, Term: term}, run: signed, round: 1, election: 1}
+		return s.signVote(candidate)
+	}
+	schedule := func(leader int32, term int32) *pb.Schedule {
+		return &pb.Schedule{Term: term, Leader: leader, Groups: []*pb.PassGroup{{Vehicles: []int32{leader}}}}
+	}
+
+	tests := []struct {
+		name        string
+		run         *Run
+		sender      int32
+		term        int32
+		schedule    *pb.Schedule
+		certificate []*pb.SignedVote
+		want        string
+	}{
+		{"certified leader", signed, 1, 1, schedule(1, 1), []*pb.SignedVote{vote(2, 1, 1), vote(3, 1, 1)}, "acknowledged"},
+		{"too few votes", signed, 1, 1, schedule(1, 1), []*pb.SignedVote{vote(2, 1, 1)}, "rejected"},
+		{"no certificate", signed, 1, 1, schedule(1, 1), nil, "rejected"},
+		{"votes of an earlier term", signed, 1, 2, schedule(1, 2), []*pb.SignedVote{vote(2, 1, 1), vote(3, 1, 1)}, "rejected"},
+		{"votes for another candidate", signed, 4, 1, schedule(4, 1), []*pb.SignedVote{vote(2, 1, 1), vote(3, 1, 1)}, "rejected"},
+		{"sender is not the leader", signed, 4, 1, schedule(1, 1), []*pb.SignedVote{vote(2, 1, 1), vote(3, 1, 1)}, "rejected"},
+		{"schedule of another term", signed, 1, 2, schedule(1, 1), []*pb.SignedVote{vote(2, 1, 1), vote(3, 1, 1)}, "rejected"},
+		{"plain votes", plain, 1, 1, schedule(1, 1), nil, "acknowledged"},
+		{"plain votes, sender is not the leader", plain, 4, 1, schedule(1, 1), nil, "rejected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &server{Vehicle: &pb.Vehicle{Number: 2, Term: 1}, run: tt.run, round: 1, election: 1, roster: roster}
+			r, err := s.CommitSchedule(context.Background(), &pb.Request{
+				Vehicle:     &pb.Vehicle{Number: tt.sender},
+				Term:        tt.term,
+				Schedule:    tt.schedule,
+				Certificate: tt.certificate,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Status != tt.want {
+				t.Fatalf("status = %q (%s), want %q", r.Status, r.Message, tt.want)
+			}
+			if committed := s.schedule != nil; committed != (tt.want == "acknowledged") {
+				t.Fatalf("committed = %v, want %v", committed, tt.want == "acknowledged")
+			}
+			if tt.want == "rejected" && s.Vehicle.Term != 1 {
+				t.Fatalf("a rejected schedule moved the vehicle to term %d", s.Vehicle.Term)
+			}
+		})
+	}
+}
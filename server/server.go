@@ -2,6 +2,7 @@
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net"
@@ -10,6 +11,7 @@ import (
 	pb "main/client/proto"
 	config "main/config"
 	direction "main/config/directionBoolean"
+	quorum "main/quorum"
 
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/proto"
@@ -20,41 +22,152 @@ var GO_SERVER_PORT = config.BasePort
 // Defines the gRPC server state, including port, vehicle info, and a mutex for safe concurrent access.
 type server struct {
 	pb.UnimplementedVehicleServiceServer
-	Port    string
-	Vehicle *pb.Vehicle
-	mu      sync.Mutex
+	Port      string
+	Vehicle   *pb.Vehicle
+	mu        sync.Mutex
+	agreement agreement
+	schedule  *pb.Schedule // passing schedule committed by the leader, nil until one is announced
+	bft       bftState     // PBFT replica state, used by the bft consensus only
+
+	run        *Run // run the server belongs to
+	address    int32
+	round      int32 // round and election of the run the server takes part in; signed votes are bound to them
+	election   int32
+	roster     quorum.Round // vehicles of the election as the quorum policy sees them
+	grpcServer *grpc.Server
 }
 
 // Function name : StartServer
-// initializes and launches a gRPC server instance for the given vehicle address.
-func StartServer(address int32, direction string, number int32, electionStatus string) (*grpc.Server, string) {
+// initializes and launches a gRPC server instance of the run for the given vehicle address and election of a round.
+func (r *Run) StartServer(address int32, direction string, number int32, licensePlate string, electionStatus string, round int32, election int32, roster quorum.Round) (*grpc.Server, string) {
+	s := newServer(r, address, direction, number, licensePlate, electionStatus)
+	s.round = round
+	s.election = election
+	s.roster = roster
+
+	if err := s.serve(); err != nil {
+		log.Printf("failed to listen: %v", err)
+		return nil, s.Port
+	}
+	// a vehicle that crashes right after becoming a candidate never answers a request of this election
+	_ = s.crashPoint("candidate")
+	return s.grpcServer, s.Port
+}
+
+// Function name: newServer
+// Builds the state of a vehicle server; with a state directory the durable vote state of the vehicle is reloaded.
+func newServer(run *Run, address int32, direction string, number int32, licensePlate string, electionStatus string) *server {
 	s := &server{
 		Port:    fmt.Sprintf("%d", GO_SERVER_PORT+int(address)),                                                      // 포트 번호를 문자열로 변환
 		Vehicle: &pb.Vehicle{Number: number, Address: address, Direction: direction, ElectionStatus: electionStatus}, // 기본 차량 정보로 초기화
+		run:     run,
+		address: address,
 	}
+	s.Vehicle.LicensePlate = licensePlate
+	// the vehicle became a candidate now; later vote updates overwrite this stamp
+	run.Stamp(number, s.Vehicle)
 
-	// make TCP listener
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", GO_SERVER_PORT+int(address)))
+	if err := s.restore(); err != nil {
+		log.Printf("failed to restore: %v", err)
+	}
+	return s
+}
+
+// Function name: serve
+// Listens on the vehicle address and serves the VehicleService handlers of s.
+func (s *server) serve() error {
+	// make listener (TCP port or in-memory, depending on the transport)
+	lis, err := s.run.Transport.Listen(s.address)
 	if err != nil {
-		log.Printf("failed to listen: %v", err)
-		return nil, s.Port
+		return err
 	}
 
 	// make gRPC server
-	grpcServer := grpc.NewServer(
-		grpc.MaxSendMsgSize(1024*1024*10), // 10MB
-		grpc.MaxRecvMsgSize(1024*1024*10), // 10MB
-	)
+	options := append([]grpc.ServerOption{
+		grpc.MaxSendMsgSize(1024 * 1024 * 10), // 10MB
+		grpc.MaxRecvMsgSize(1024 * 1024 * 10), // 10MB
+	}, s.run.Transport.ServerOptions()...)
+	security, err := s.securityOptions()
+	if err != nil {
+		lis.Close()
+		return err
+	}
+	options = append(options, security...)
+	grpcServer := grpc.NewServer(options...)
 	pb.RegisterVehicleServiceServer(grpcServer, s)
 
+	s.grpcServer = grpcServer
+
 	// start server
 	go func() {
 		defer lis.Close()
-		if err := grpcServer.Serve(lis); err != nil {
-			fmt.Printf("failed to serve: %v", err)
+		// a crash or the end of the election stops the server, which is not a failure
+		if err := grpcServer.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) && !errors.Is(err, net.ErrClosed) {
+			log.Printf("failed to serve address %d: %v", s.address, err)
 		}
 	}()
-	return grpcServer, s.Port
+	return nil
+}
+
+// Function name: TakeStaleRejections
+// Returns how many requests were rejected for a stale term and resets the counter.
+func (r *Run) TakeStaleRejections() int {
+	return int(r.staleRejections.Swap(0))
+}
+
+// Function name: TakeDoubleVotes
+// Returns how many votes were granted to a second candidate of the same term and forgets the granted votes.
+func (r *Run) TakeDoubleVotes() int {
+	r.ballots.mu.Lock()
+	defer r.ballots.mu.Unlock()
+	double := r.ballots.double
+	r.ballots.votes = nil
+	r.ballots.double = 0
+	return double
+}
+
+// Function name: recordVote
+// Records that the vehicle granted its vote of the term to the candidate.
+func (r *Run) recordVote(number int32, term int32, candidate int32) {
+	r.ballots.mu.Lock()
+	defer r.ballots.mu.Unlock()
+	if r.ballots.votes == nil {
+		r.ballots.votes = make(map[[2]int32]int32)
+	}
+	key := [2]int32{number, term}
+	if voted, ok := r.ballots.votes[key]; ok && voted != candidate {
+		r.ballots.double++
+	}
+	r.ballots.votes[key] = candidate
+}
+
+// Function name: checkTerm
+// Applies the Raft term rules to an incoming request. A request from an older term is rejected with this
+// vehicle's state so the sender can catch up; a request from a newer term makes this vehicle step down to
+// a fresh candidate of that term, which may vote again and forgets the RandomAgreement draw of the old term.
+// Must be called with s.mu held.
+func (s *server) checkTerm(req *pb.Request) *pb.Response {
+	if req.Term < s.Vehicle.Term {
+		s.run.staleRejections.Add(1)
+		return &pb.Response{
+			Message: fmt.Sprintf("Vehicle %d is in term %d, request is from term %d", s.Vehicle.Number, s.Vehicle.Term, req.Term),
+			Status:  "rejected",
+			Vehicle: proto.Clone(s.Vehicle).(*pb.Vehicle),
+		}
+	}
+
+	if req.Term > s.Vehicle.Term {
+		s.Vehicle.Term = req.Term
+		s.Vehicle.SendVotes = 0
+		s.Vehicle.ReceiveVotes = 0
+		s.Vehicle.ElectionVote = 0
+		s.Vehicle.ElectionStatus = "Candidate"
+		s.run.Stamp(s.Vehicle.Number, s.Vehicle)
+		s.Vehicle.Leader = 0
+		s.schedule = nil
+		s.agreement = agreement{}
+	}
+	return nil
 }
 
 // Function name: ReceiveRequest
@@ -67,24 +180,41 @@ func (s *server) ReceiveRequest(ctx context.Context, req *pb.Request) (*pb.Respo
 		return nil, fmt.Errorf("received nil request")
 	}
 
-	if s.Vehicle.SendVotes == 0 {
+	if rejected := s.checkTerm(req); rejected != nil {
+		return rejected, nil
+	}
+	s.run.Observe(s.Vehicle.Number, req.Vehicle)
+
+	// an equivocating or vote_all vehicle grants its vote to every candidate that asks
+	if s.Vehicle.SendVotes == 0 || s.votesForAll() {
 		// Process the incoming vote
 		s.Vehicle.SendVotes = 1
+		s.run.recordVote(s.Vehicle.Number, s.Vehicle.Term, req.Vehicle.Number)
 
 		// Validate direction compatibility and build the response message
+		reported := s.reportedDirection(req.Vehicle.Direction)
 		directionStatus := "False"
-		if direction.DirectionBoolean(req.Vehicle.Direction, s.Vehicle.Direction) {
+		if direction.DirectionBoolean(req.Vehicle.Direction, reported) {
 			directionStatus = "True"
 		}
 
+		if err := s.persist(); err != nil {
+			return nil, err
+		}
+		if err := s.crashPoint("vote"); err != nil {
+			return nil, err
+		}
+
 		vehicleCopy := proto.Clone(s.Vehicle).(*pb.Vehicle)
+		vehicleCopy.Direction = reported
 
 		response := &pb.Response{
 			Message:           fmt.Sprintf("Vote registered from port %s to port %s", req.Port, s.Port),
 			Status:            "acknowledged",
 			DirectionStatus:   directionStatus,
-			ResponseDirection: s.Vehicle.Direction,
+			ResponseDirection: reported,
 			Vehicle:           vehicleCopy,
+			Vote:              s.signVote(req.Vehicle.Number),
 		}
 		return response, nil
 	} else {
@@ -102,6 +232,25 @@ func (s *server) ReceiveRequest(ctx context.Context, req *pb.Request) (*pb.Respo
 // Function name: LeaderElection
 // Handles leader election requests and updates vehicle roles based on vote counts and timestamps.
 func (s *server) LeaderElection(ctx context.Context, req *pb.Request) (*pb.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rejected := s.checkTerm(req); rejected != nil {
+		return rejected, nil
+	}
+	s.run.Observe(s.Vehicle.Number, req.Vehicle)
+
+	// an equivocating vehicle acknowledges every candidate, whatever their votes
+	if s.strategy() == "equivocate" {
+		s.Vehicle.ElectionStatus = "Follower"
+		s.Vehicle.Leader = req.Vehicle.Number
+		return &pb.Response{
+			Message: fmt.Sprintf("Vehicle %d follows %d", s.Vehicle.Number, req.Vehicle.Number),
+			Status:  "acknowledged",
+			Vehicle: proto.Clone(s.Vehicle).(*pb.Vehicle),
+		}, nil
+	}
+
 	if req.Vehicle.ElectionStatus == "Follower" {
 		vehicleCopy := proto.Clone(s.Vehicle).(*pb.Vehicle)
 
@@ -113,6 +262,11 @@ func (s *server) LeaderElection(ctx context.Context, req *pb.Request) (*pb.Respo
 		return response, nil
 	}
 
+	// a candidate only wins on the votes its certificate proves
+	if rejected := s.checkCertificate(req); rejected != nil {
+		return rejected, nil
+	}
+
 	if s.Vehicle.ReceiveVotes < req.Vehicle.ReceiveVotes {
 
 		vehicleCopy := proto.Clone(s.Vehicle).(*pb.Vehicle)
@@ -124,15 +278,38 @@ func (s *server) LeaderElection(ctx context.Context, req *pb.Request) (*pb.Respo
 		}
 		// update this server as follower with newer vote info
 		s.Vehicle.ElectionStatus = "Follower"
+		s.Vehicle.Leader = req.Vehicle.Number
 		s.Vehicle.ReceiveVotes = req.Vehicle.ReceiveVotes
-		s.Vehicle.ElectionTime = req.Vehicle.ElectionTime
+		adoptStamp(s.Vehicle, req.Vehicle)
+		if err := s.persist(); err != nil {
+			return nil, err
+		}
+		if err := s.crashPoint("follow"); err != nil {
+			return nil, err
+		}
 		return response, nil
 	} else {
 		if s.Vehicle.ReceiveVotes == req.Vehicle.ReceiveVotes {
-			reqTime := req.Vehicle.ElectionTime.AsTime().UnixNano()
-			serverTime := s.Vehicle.ElectionTime.AsTime().UnixNano()
+			// the stamp this server holds is its own, or the one of the leader it follows
+			holder := s.Vehicle.Number
+			if s.Vehicle.Leader != 0 {
+				holder = s.Vehicle.Leader
+			}
 
-			if reqTime > serverTime {
+			// tie on votes: the tie-break order of the run decides, unless the RandomAgreement draw does
+			requestWins := s.run.laterStamp(req.Vehicle, req.Vehicle.Number, s.Vehicle, holder)
+			if wins, ok := s.winsRandomTie(req, holder); ok {
+				requestWins = wins
+			}
+			if holder != req.Vehicle.Number {
+				if requestWins {
+					s.run.recordTie(req.Vehicle.Number, holder)
+				} else {
+					s.run.recordTie(holder, req.Vehicle.Number)
+				}
+			}
+
+			if requestWins {
 
 				vehicleCopy := proto.Clone(s.Vehicle).(*pb.Vehicle)
 
@@ -143,8 +320,15 @@ func (s *server) LeaderElection(ctx context.Context, req *pb.Request) (*pb.Respo
 				}
 				// request has newer timestamp → this server becomes follower
 				s.Vehicle.ElectionStatus = "Follower"
+				s.Vehicle.Leader = req.Vehicle.Number
 				s.Vehicle.ReceiveVotes = req.Vehicle.ReceiveVotes
-				s.Vehicle.ElectionTime = req.Vehicle.ElectionTime
+				adoptStamp(s.Vehicle, req.Vehicle)
+				if err := s.persist(); err != nil {
+					return nil, err
+				}
+				if err := s.crashPoint("follow"); err != nil {
+					return nil, err
+				}
 				return response, nil
 			} else {
 				vehicleCopy := proto.Clone(s.Vehicle).(*pb.Vehicle)
@@ -157,7 +341,7 @@ func (s *server) LeaderElection(ctx context.Context, req *pb.Request) (*pb.Respo
 				// this server has newer timestamp → request vehicle becomes follower
 				req.Vehicle.ElectionStatus = "Follower"
 				req.Vehicle.ReceiveVotes = s.Vehicle.ReceiveVotes
-				req.Vehicle.ElectionTime = s.Vehicle.ElectionTime
+				adoptStamp(req.Vehicle, s.Vehicle)
 				return response, nil
 			}
 		}
@@ -172,7 +356,7 @@ func (s *server) LeaderElection(ctx context.Context, req *pb.Request) (*pb.Respo
 		}
 		req.Vehicle.ElectionStatus = "Follower"
 		req.Vehicle.ReceiveVotes = s.Vehicle.ReceiveVotes
-		req.Vehicle.ElectionTime = s.Vehicle.ElectionTime
+		adoptStamp(req.Vehicle, s.Vehicle)
 		return response, nil
 	}
 }
@@ -180,10 +364,22 @@ func (s *server) LeaderElection(ctx context.Context, req *pb.Request) (*pb.Respo
 // Function name: UpdateVoteCount
 // Determines the leader by comparing vote counts and timestamps, updating roles for both vehicles.
 func (s *server) UpdateVoteCount(ctx context.Context, req *pb.Request) (*pb.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rejected := s.checkTerm(req); rejected != nil {
+		return rejected, nil
+	}
 
 	if s.Vehicle.Number == req.Vehicle.Number {
+		if rejected := s.checkCertificate(req); rejected != nil {
+			return rejected, nil
+		}
 		s.Vehicle.ReceiveVotes = req.Vehicle.ReceiveVotes
-		s.Vehicle.ElectionTime = req.Vehicle.ElectionTime
+		adoptStamp(s.Vehicle, req.Vehicle)
+		if err := s.persist(); err != nil {
+			return nil, err
+		}
 
 		return &pb.Response{
 			Message: fmt.Sprintf("Vote count updated for vehicle %d.\n", s.Vehicle.Number),
@@ -196,3 +392,16 @@ func (s *server) UpdateVoteCount(ctx context.Context, req *pb.Request) (*pb.Resp
 		}, nil
 	}
 }
+
+// Function name: GetState
+// Returns this vehicle's current view of the election: status, followed leader, term, votes and ElectionTime.
+func (s *server) GetState(ctx context.Context, req *pb.Request) (*pb.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return &pb.Response{
+		Message: fmt.Sprintf("State of vehicle %d", s.Vehicle.Number),
+		Status:  "acknowledged",
+		Vehicle: proto.Clone(s.Vehicle).(*pb.Vehicle),
+	}, nil
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	pb "main/client/proto"
+
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// storedState is the file layout of one vehicle's durable state.
+type storedState struct {
+	Vehicle  json.RawMessage `json:"vehicle"`
+	Schedule json.RawMessage `json:"schedule,omitempty"`
+	Bft      *storedBft      `json:"bft,omitempty"`
+}
+
+// storedBft is the part of the PBFT replica state a replica must not forget: the roster, its view,
+// the proposals it accepted and its lock. Prepare and Commit sets are collected again after a restart.
+type storedBft struct {
+	Roster     []int32                   `json:"roster"`
+	View       int32                     `json:"view"`
+	Accepted   map[int32]map[string]bool `json:"accepted"`
+	Locked     json.RawMessage           `json:"locked,omitempty"`
+	LockedView int32                     `json:"locked_view"`
+}
+
+// Function name: statePath
+// Returns the state file of the vehicle.
+func (r *Run) statePath(number int32) string {
+	return filepath.Join(r.StateDir, fmt.Sprintf("vehicle-%d.json", number))
+}
+
+// Function name: persist
+// Writes the vote and election state of the vehicle to its state file before a reply leaves the server.
+// The file is written next to the old one, synced and renamed over it, so a crash leaves either the old
+// or the new state on disk, never a partial one. Must be called with s.mu held.
+func (s *server) persist() error {
+	if s.run.StateDir == "" {
+		return nil
+	}
+
+	var state storedState
+	var err error
+	if state.Vehicle, err = protojson.Marshal(s.Vehicle); err != nil {
+		return err
+	}
+	if s.schedule != nil {
+		if state.Schedule, err = protojson.Marshal(s.schedule); err != nil {
+			return err
+		}
+	}
+	if len(s.bft.roster) > 0 {
+		state.Bft = &storedBft{Roster: s.bft.roster, View: s.bft.view, Accepted: s.bft.accepted, LockedView: s.bft.lockedView}
+		if s.bft.locked != nil {
+			if state.Bft.Locked, err = protojson.Marshal(s.bft.locked); err != nil {
+				return err
+			}
+		}
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	path := s.run.statePath(s.Vehicle.Number)
+	tmp, err := os.CreateTemp(s.run.StateDir, filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// sync the directory too, so the rename itself survives a crash
+	dir, err := os.Open(s.run.StateDir)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
+
+// Function name: restore
+// Loads the state file of the vehicle, if there is one, over its fresh state.
+// The identity of the vehicle (number, address, direction, plate) always comes from the caller.
+func (s *server) restore() error {
+	if s.run.StateDir == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.run.statePath(s.Vehicle.Number))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var state storedState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("state of vehicle %d: %v", s.Vehicle.Number, err)
+	}
+	stored := &pb.Vehicle{}
+	if err := protojson.Unmarshal(state.Vehicle, stored); err != nil {
+		return fmt.Errorf("state of vehicle %d: %v", s.Vehicle.Number, err)
+	}
+	if len(state.Schedule) > 0 {
+		schedule := &pb.Schedule{}
+		if err := protojson.Unmarshal(state.Schedule, schedule); err != nil {
+			return fmt.Errorf("state of vehicle %d: %v", s.Vehicle.Number, err)
+		}
+		s.schedule = schedule
+	}
+	if state.Bft != nil {
+		s.bft = bftState{roster: state.Bft.Roster, view: state.Bft.View, accepted: state.Bft.Accepted, lockedView: state.Bft.LockedView}
+		if len(state.Bft.Locked) > 0 {
+			locked := &pb.Schedule{}
+			if err := protojson.Unmarshal(state.Bft.Locked, locked); err != nil {
+				return fmt.Errorf("state of vehicle %d: %v", s.Vehicle.Number, err)
+			}
+			s.bft.locked = locked
+		}
+	}
+
+	s.Vehicle.Term = stored.Term
+	s.Vehicle.SendVotes = stored.SendVotes
+	s.Vehicle.ReceiveVotes = stored.ReceiveVotes
+	s.Vehicle.ElectionVote = stored.ElectionVote
+	s.Vehicle.ElectionStatus = stored.ElectionStatus
+	adoptStamp(s.Vehicle, stored)
+	s.Vehicle.Leader = stored.Leader
+	return nil
+}
+
+// Function name: ClearState
+// Removes the state files of the finished election, so the next election of a vehicle starts fresh.
+func (r *Run) ClearState() error {
+	if r.StateDir == "" {
+		return nil
+	}
+	files, err := filepath.Glob(filepath.Join(r.StateDir, "vehicle-*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	pb "main/client/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRestartedServerVotesOncePerTerm(t *testing.T) {
+	tests := []struct {
+		name    string
+		durable bool
+		second  string // status of the vote request of another candidate after the restart
+		double  int
+	}{
+		{"durable state", true, "ignored", 0},
+		{"state in memory only", false, "acknowledged", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := NewRun()
+			if tt.durable {
+				run.StateDir = t.TempDir()
+			}
+			vote := func(s *server, candidate int32) string {
+				r, err := s.ReceiveRequest(context.Background(), &pb.Request{Term: 2, Vehicle: &pb.Vehicle{Number: candidate, ElectionStatus: "Candidate"}})
+				if err != nil {
+					t.Fatal(err)
+				}
+				return r.Status
+			}
+
+			if status := vote(newServer(run, 2, "Ll", 2, "plate", "Candidate"), 1); status != "acknowledged" {
+				t.Fatalf("first vote: %q, want acknowledged", status)
+			}
+			restarted := newServer(run, 2, "Ll", 2, "plate", "Candidate")
+			if status := vote(restarted, 3); status != tt.second {
+				t.Fatalf("vote after the restart: %q, want %q", status, tt.second)
+			}
+			if double := run.TakeDoubleVotes(); double != tt.double {
+				t.Fatalf("%d double votes, want %d", double, tt.double)
+			}
+		})
+	}
+}
+
+func TestPersistRestore(t *testing.T) {
+	run := NewRun()
+	run.StateDir = t.TempDir()
+	s := newServer(run, 2, "Ll", 2, "plate", "Candidate")
+	s.Vehicle.Term = 3
+	s.Vehicle.SendVotes = 1
+	s.Vehicle.ReceiveVotes = 2
+	s.Vehicle.ElectionStatus = "Follower"
+	s.Vehicle.Leader = 4
+	s.schedule = &pb.Schedule{Term: 3, Leader: 4, Groups: []*pb.PassGroup{{Vehicles: []int32{4, 2}}}}
+	s.bft = bftState{roster: []int32{1, 2, 3, 4}, view: 2, accepted: map[int32]map[string]bool{2: {"digest": true}}, locked: s.schedule, lockedView: 2}
+	if err := s.persist(); err != nil {
+		t.Fatal(err)
+	}
+	// an older state file is replaced, not appended to
+	s.Vehicle.ReceiveVotes = 3
+	if err := s.persist(); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := newServer(run, 2, "Ll", 2, "plate", "Candidate")
+	if !proto.Equal(restored.Vehicle, s.Vehicle) {
+		t.Fatalf("vehicle = %v, want %v", restored.Vehicle, s.Vehicle)
+	}
+	if !proto.Equal(restored.schedule, s.schedule) || !proto.Equal(restored.bft.locked, s.bft.locked) {
+		t.Fatalf("schedule %v, lock %v, want %v", restored.schedule, restored.bft.locked, s.schedule)
+	}
+	if restored.bft.view != 2 || restored.bft.lockedView != 2 || !restored.bft.accepted[2]["digest"] || len(restored.bft.roster) != 4 {
+		t.Fatalf("bft state = %+v", restored.bft)
+	}
+
+	// only the state file is left: the temporary file is renamed over it
+	files, err := os.ReadDir(run.StateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name() != "vehicle-2.json" {
+		t.Fatalf("state directory holds %v", files)
+	}
+	if err := run.ClearState(); err != nil {
+		t.Fatal(err)
+	}
+	if fresh := newServer(run, 2, "Ll", 2, "plate", "Candidate"); fresh.Vehicle.Term != 0 || fresh.schedule != nil {
+		t.Fatalf("state survived ClearState: %v", fresh.Vehicle)
+	}
+}
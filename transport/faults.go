@@ -0,0 +1,236 @@
+package transport
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	clock "main/clock"
+	config "main/config"
+	utills "main/utills"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Metadata keys that carry the link of an RPC: the sending vehicle for the client interceptors of a faulty
+// network and of a partition, and both ends for the server interceptor of a partition.
+const (
+	fromKey = "vehicle-from"
+	toKey   = "vehicle-to"
+)
+
+// Function name: WithSource
+// Marks the RPCs sent with the context as sent by the given vehicle. Vehicle 0 is the
+// simulation itself (e.g. reading the final state of the servers); its RPCs and the RPCs
+// a vehicle sends to its own server are never faulted.
+func WithSource(ctx context.Context, from int32) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, fromKey, strconv.Itoa(int(from)))
+}
+
+// FaultCounts counts the faults injected since the last Take.
+type FaultCounts struct {
+	Dropped    int
+	Duplicated int
+	Reordered  int
+}
+
+// Faulty wraps a transport with a unary client interceptor that injects the network faults of a scenario:
+// the request leg (drop, latency, reordering, duplication) before the call and the response leg (drop, latency)
+// after it. The sending side sees both legs, so the vehicle servers need no interceptor of their own.
+// All delays go through the simulation clock and are spent outside the call itself,
+// so a virtual clock never waits on a handler that is asleep.
+type Faulty struct {
+	Transport
+	network    config.Network
+	clock      clock.Clock
+	rand       *utills.LockedRand
+	dropped    atomic.Int64
+	duplicated atomic.Int64
+	reordered  atomic.Int64
+
+	mu         sync.Mutex
+	held       map[int32][]chan struct{} // reordered requests waiting for a later request to the same vehicle
+	duplicates sync.WaitGroup            // duplicate copies still in flight
+}
+
+// Function name: NewFaulty
+// Wraps the transport with the faults of the network description.
+func NewFaulty(inner Transport, network config.Network, clk clock.Clock, r *utills.LockedRand) *Faulty {
+	return &Faulty{Transport: inner, network: network, clock: clk, rand: r, held: make(map[int32][]chan struct{})}
+}
+
+// Function name: Dial
+// Opens a connection to the vehicle address whose RPCs pass the client interceptor.
+func (f *Faulty) Dial(address int32, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	faulty := append(opts[:len(opts):len(opts)], grpc.WithChainUnaryInterceptor(f.clientInterceptor(address, opts)))
+	return f.Transport.Dial(address, faulty...)
+}
+
+// Function name: Flush
+// Waits until every duplicate copy sent so far has been delivered or lost.
+func (f *Faulty) Flush() {
+	f.duplicates.Wait()
+}
+
+// Function name: Take
+// Returns the number of injected faults and resets the counters.
+func (f *Faulty) Take() FaultCounts {
+	return FaultCounts{
+		Dropped:    int(f.dropped.Swap(0)),
+		Duplicated: int(f.duplicated.Swap(0)),
+		Reordered:  int(f.reordered.Swap(0)),
+	}
+}
+
+// Function name: clientInterceptor
+// Applies the faults of the link from the sending vehicle to the given address and of the link back.
+// The dial options are kept to open the connection of a duplicate copy, which outlives the call.
+func (f *Faulty) clientInterceptor(to int32, dialOpts []grpc.DialOption) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		from := source(ctx)
+		if from == 0 || from == to {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		faults := f.network.LinkFaults(from, to)
+		if f.chance(faults.Drop) {
+			f.dropped.Add(1)
+			return status.Errorf(codes.Unavailable, "request from %d to %d dropped", from, to)
+		}
+		f.clock.Sleep(f.latency(faults.Latency))
+		if f.chance(faults.Reorder) {
+			f.reordered.Add(1)
+			f.hold(to, time.Duration(faults.ReorderDelayMs*float64(time.Millisecond)))
+		}
+
+		if err := invoker(ctx, method, req, reply, cc, opts...); err != nil {
+			return err
+		}
+		f.delivered(to)
+
+		// the copy arrives one more link delay later, without holding up the sender
+		if f.chance(faults.Duplicate) {
+			f.duplicated.Add(1)
+			f.duplicate(ctx, to, dialOpts, method, req, reply, f.latency(faults.Latency))
+		}
+
+		// the server has handled the request; only its response can still be lost or delayed
+		back := f.network.LinkFaults(to, from)
+		if f.chance(back.Drop) {
+			f.dropped.Add(1)
+			return status.Errorf(codes.Unavailable, "response from %d to %d dropped", to, from)
+		}
+		f.clock.Sleep(f.latency(back.Latency))
+		return nil
+	}
+}
+
+// Function name: hold
+// Holds a reordered request back until a later request to the same vehicle has been delivered,
+// or for at most the given delay if no other request to it arrives.
+func (f *Faulty) hold(to int32, limit time.Duration) {
+	release := make(chan struct{})
+	f.mu.Lock()
+	f.held[to] = append(f.held[to], release)
+	f.mu.Unlock()
+
+	timer := f.clock.NewTimer(limit)
+	select {
+	case <-release:
+		timer.Stop()
+	case <-timer.C():
+		f.mu.Lock()
+		for i, c := range f.held[to] {
+			if c == release {
+				f.held[to] = append(f.held[to][:i], f.held[to][i+1:]...)
+				break
+			}
+		}
+		f.mu.Unlock()
+	}
+}
+
+// Function name: delivered
+// Lets every request held back for the vehicle go, now that a later one has overtaken them.
+func (f *Faulty) delivered(to int32) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, release := range f.held[to] {
+		close(release)
+	}
+	delete(f.held, to)
+}
+
+// Function name: duplicate
+// Sends a second copy of a delivered request after the given delay on a connection of its own, so the copy
+// arrives even after the sender has closed its connection; the response to the copy is discarded.
+func (f *Faulty) duplicate(ctx context.Context, to int32, dialOpts []grpc.DialOption, method string, req, reply any, delay time.Duration) {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	f.duplicates.Add(1)
+	go func() {
+		defer f.duplicates.Done()
+		f.clock.Sleep(delay)
+
+		conn, err := f.Transport.Dial(to, dialOpts...)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(metadata.NewOutgoingContext(context.Background(), md), 2*time.Second)
+		defer cancel()
+		copied := reply.(proto.Message).ProtoReflect().New().Interface()
+		_ = conn.Invoke(ctx, method, req, copied)
+	}()
+}
+
+// Function name: latency
+// Draws one message delay from the distribution.
+func (f *Faulty) latency(l config.Latency) time.Duration {
+	var ms float64
+	switch l.Distribution {
+	case "constant":
+		ms = l.MeanMs
+	case "uniform":
+		ms = l.MinMs + f.rand.Float64()*(l.MaxMs-l.MinMs)
+	case "normal":
+		ms = l.MeanMs + f.rand.NormFloat64()*l.StddevMs
+	case "exponential":
+		ms = f.rand.ExpFloat64() * l.MeanMs
+	}
+	if ms < l.MinMs {
+		ms = l.MinMs
+	}
+	if l.MaxMs > 0 && ms > l.MaxMs {
+		ms = l.MaxMs
+	}
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// Function name: chance
+// Returns true with probability p.
+func (f *Faulty) chance(p float64) bool {
+	return p > 0 && f.rand.Float64() < p
+}
+
+// Function name: source
+// Returns the sending vehicle stored in the outgoing metadata, 0 if none.
+func source(ctx context.Context) int32 {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	return parseVehicle(md.Get(fromKey))
+}
+
+// Function name: parseVehicle
+// Parses the first vehicle number of a metadata value, 0 if it is missing.
+func parseVehicle(values []string) int32 {
+	if len(values) == 0 {
+		return 0
+	}
+	n, _ := strconv.Atoi(values[0])
+	return int32(n)
+}
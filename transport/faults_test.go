@@ -0,0 +1,164 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	pb "main/client/proto"
+	clock "main/clock"
+	config "main/config"
+	utills "main/utills"
+
+	"google.golang.org/grpc"
+)
+
+// recorder is a vehicle server that records the sender of every request it handles, in order.
+type recorder struct {
+	pb.UnimplementedVehicleServiceServer
+	mu      sync.Mutex
+	senders []int32
+}
+
+func (r *recorder) GetState(ctx context.Context, req *pb.Request) (*pb.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.senders = append(r.senders, req.Vehicle.Number)
+	return &pb.Response{Status: "acknowledged"}, nil
+}
+
+func (r *recorder) received() []int32 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int32(nil), r.senders...)
+}
+
+// Function name: sendAll
+// Sends one request from every sender to vehicle 2 over a faulty network with a fixed seed. Each request is
+// sent once the one before it has returned or is held back for reordering. Returns the senders in the order
+// the server handled their requests, the requests that failed and the injected faults.
+func sendAll(t *testing.T, network config.Network, senders []int32) ([]int32, int, FaultCounts) {
+	t.Helper()
+	memory := NewMemory()
+	lis, err := memory.Listen(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &recorder{}
+	grpcServer := grpc.NewServer()
+	pb.RegisterVehicleServiceServer(grpcServer, server)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	faulty := NewFaulty(memory, network, clock.Real{}, utills.NewLockedStream(1, "network"))
+	var failed int
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, from := range senders {
+		held := faulty.heldFor(2)
+		returned := make(chan struct{})
+		wg.Add(1)
+		go func(from int32) {
+			defer wg.Done()
+			defer close(returned)
+			conn, err := faulty.Dial(2)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer conn.Close()
+			ctx, cancel := context.WithTimeout(WithSource(context.Background(), from), 5*time.Second)
+			defer cancel()
+			if _, err := pb.NewVehicleServiceClient(conn).GetState(ctx, &pb.Request{Vehicle: &pb.Vehicle{Number: from}}); err != nil {
+				mu.Lock()
+				failed++
+				mu.Unlock()
+			}
+		}(from)
+
+		deadline := time.Now().Add(5 * time.Second)
+	wait:
+		for time.Now().Before(deadline) {
+			select {
+			case <-returned:
+				break wait
+			default:
+			}
+			if faulty.heldFor(2) > held {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	wg.Wait()
+	faulty.Flush()
+	return server.received(), failed, faulty.Take()
+}
+
+// Function name: heldFor
+// Returns how many requests to the vehicle are held back right now.
+func (f *Faulty) heldFor(to int32) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.held[to])
+}
+
+func TestFaulty(t *testing.T) {
+	link := func(from int32, to int32, faults config.Faults) config.Network {
+		return config.Network{Links: []config.Link{{From: from, To: to, Faults: faults}}}
+	}
+	tests := []struct {
+		name     string
+		network  config.Network
+		senders  []int32
+		received []int32
+		failed   int
+		faults   FaultCounts
+	}{
+		{"perfect network", config.Network{}, []int32{1, 3}, []int32{1, 3}, 0, FaultCounts{}},
+		{"request dropped", config.Network{Faults: config.Faults{Drop: 1}}, []int32{1}, nil, 1, FaultCounts{Dropped: 1}},
+		{"response dropped", link(2, 1, config.Faults{Drop: 1}), []int32{1}, []int32{1}, 1, FaultCounts{Dropped: 1}},
+		{"simulation is never faulted", config.Network{Faults: config.Faults{Drop: 1}}, []int32{0}, []int32{0}, 0, FaultCounts{}},
+		{"request duplicated", config.Network{Faults: config.Faults{Duplicate: 1}}, []int32{1}, []int32{1, 1}, 0, FaultCounts{Duplicated: 1}},
+		{"reordered request overtaken", link(1, 2, config.Faults{Reorder: 1, ReorderDelayMs: 10000}), []int32{1, 3}, []int32{3, 1}, 0, FaultCounts{Reordered: 1}},
+		{"reordered request released after its delay", link(1, 2, config.Faults{Reorder: 1, ReorderDelayMs: 20}), []int32{1}, []int32{1}, 0, FaultCounts{Reordered: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			received, failed, faults := sendAll(t, tt.network, tt.senders)
+			if !reflect.DeepEqual(received, tt.received) {
+				t.Fatalf("received from %v, want %v", received, tt.received)
+			}
+			if failed != tt.failed {
+				t.Fatalf("%d requests failed, want %d", failed, tt.failed)
+			}
+			if faults != tt.faults {
+				t.Fatalf("faults = %+v, want %+v", faults, tt.faults)
+			}
+			// an overtaken request is released at once, not after its reorder delay
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Fatalf("took %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestFaultySeedReproducible(t *testing.T) {
+	network := config.Network{Faults: config.Faults{Drop: 0.3, Duplicate: 0.3}}
+	senders := []int32{1, 3, 4, 5, 1, 3, 4, 5, 1, 3, 4, 5}
+	var runs []string
+	for run := 0; run < 2; run++ {
+		received, failed, faults := sendAll(t, network, senders)
+		// a duplicate copy is sent in the background, so only the requests delivered are compared, not their order
+		sort.Slice(received, func(i, j int) bool { return received[i] < received[j] })
+		runs = append(runs, fmt.Sprint(received, failed, faults))
+	}
+	if runs[0] != runs[1] {
+		t.Fatalf("two runs with the same seed differ:\n%s\n%s", runs[0], runs[1])
+	}
+}
@@ -0,0 +1,122 @@
+package transport
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	clock "main/clock"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Partition wraps a transport so that vehicles in different groups cannot reach each other
+// while the partition is active. Both the client and the server interceptor enforce it, so a request
+// is lost if the partition is active when it is sent or when it arrives, and a response if it is active
+// when the response is sent.
+type Partition struct {
+	Transport
+	clock   clock.Clock
+	mu      sync.Mutex
+	group   map[int32]int
+	start   time.Time
+	heal    time.Time // zero: the partition does not heal
+	blocked atomic.Int64
+}
+
+// Function name: NewPartition
+// Wraps the transport with a partition that is inactive until Split is called.
+func NewPartition(inner Transport, clk clock.Clock) *Partition {
+	return &Partition{Transport: inner, clock: clk}
+}
+
+// Function name: Split
+// Separates the groups from start until heal (never if heal is zero); vehicles in no group are not cut off.
+func (p *Partition) Split(groups [][]int32, start time.Time, heal time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.group = make(map[int32]int)
+	for g, vehicles := range groups {
+		for _, v := range vehicles {
+			p.group[v] = g + 1
+		}
+	}
+	p.start = start
+	p.heal = heal
+}
+
+// Function name: Blocked
+// Reports whether a message from one vehicle to another is cut off by the partition now.
+func (p *Partition) Blocked(from int32, to int32) bool {
+	if from == 0 || from == to {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.clock.Now()
+	if now.Before(p.start) || (!p.heal.IsZero() && !now.Before(p.heal)) {
+		return false
+	}
+	a, b := p.group[from], p.group[to]
+	return a != 0 && b != 0 && a != b
+}
+
+// Function name: TakeBlocked
+// Returns the number of messages lost to the partition and resets the counter.
+func (p *Partition) TakeBlocked() int {
+	return int(p.blocked.Swap(0))
+}
+
+// Function name: Dial
+// Opens a connection to the vehicle address whose RPCs pass the partition check.
+func (p *Partition) Dial(address int32, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	opts = append(opts, grpc.WithChainUnaryInterceptor(p.clientInterceptor(address)))
+	return p.Transport.Dial(address, opts...)
+}
+
+// Function name: ServerOptions
+// Adds the server-side partition check to the options of the wrapped transport.
+func (p *Partition) ServerOptions() []grpc.ServerOption {
+	return append(p.Transport.ServerOptions(), grpc.ChainUnaryInterceptor(p.serverInterceptor))
+}
+
+// Function name: clientInterceptor
+// Drops requests that are sent across the partition.
+func (p *Partition) clientInterceptor(to int32) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		from := source(ctx)
+		if p.Blocked(from, to) {
+			p.blocked.Add(1)
+			return status.Errorf(codes.Unavailable, "vehicle %d cannot reach vehicle %d: partitioned", from, to)
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, toKey, strconv.Itoa(int(to)))
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// Function name: serverInterceptor
+// Drops requests that arrive and responses that are sent across the partition.
+func (p *Partition) serverInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	from := parseVehicle(md.Get(fromKey))
+	to := parseVehicle(md.Get(toKey))
+
+	if p.Blocked(from, to) {
+		p.blocked.Add(1)
+		return nil, status.Errorf(codes.Unavailable, "vehicle %d cannot reach vehicle %d: partitioned", from, to)
+	}
+	resp, err := handler(ctx, req)
+	if err == nil && p.Blocked(to, from) {
+		p.blocked.Add(1)
+		return nil, status.Errorf(codes.Unavailable, "vehicle %d cannot reach vehicle %d: partitioned", to, from)
+	}
+	return resp, err
+}
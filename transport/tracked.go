@@ -0,0 +1,38 @@
+package transport
+
+import (
+	"context"
+
+	clock "main/clock"
+
+	"google.golang.org/grpc"
+)
+
+// Tracked wraps a transport so that a virtual clock sees every RPC in flight.
+// It must be the innermost wrapper: modelled link delays of the outer wrappers are clock sleeps,
+// and a sleep inside a tracked call would keep the clock from ever reaching its wake-up instant.
+type Tracked struct {
+	Transport
+	tracker clock.Tracker
+}
+
+// Function name: NewTracked
+// Wraps the transport so that the tracker is told when each call starts and ends.
+func NewTracked(inner Transport, tracker clock.Tracker) *Tracked {
+	return &Tracked{Transport: inner, tracker: tracker}
+}
+
+// Function name: Dial
+// Opens a connection to the vehicle address whose RPCs are reported to the tracker.
+func (t *Tracked) Dial(address int32, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	opts = append(opts, grpc.WithChainUnaryInterceptor(t.clientInterceptor))
+	return t.Transport.Dial(address, opts...)
+}
+
+// Function name: clientInterceptor
+// Holds the clock for the whole round trip of the call, including the server handler.
+func (t *Tracked) clientInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	t.tracker.BeginCall()
+	defer t.tracker.EndCall()
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
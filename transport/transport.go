@@ -0,0 +1,106 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Transport carries VehicleService RPCs between vehicle nodes.
+// A vehicle server listens on its address and other vehicles dial that address.
+// ServerOptions are added to every vehicle server, e.g. the server interceptor of a partition.
+type Transport interface {
+	Listen(address int32) (net.Listener, error)
+	Dial(address int32, opts ...grpc.DialOption) (*grpc.ClientConn, error)
+	ServerOptions() []grpc.ServerOption
+}
+
+// Function name: New
+// Returns the transport selected by name: "tcp" (default) or "memory".
+func New(name string, basePort int) Transport {
+	if name == "memory" {
+		return NewMemory()
+	}
+	return TCP{BasePort: basePort}
+}
+
+// TCP serves every vehicle on localhost:BasePort+address.
+type TCP struct {
+	BasePort int
+}
+
+// Function name: Listen
+// Opens the TCP port of the given vehicle address.
+func (t TCP) Listen(address int32) (net.Listener, error) {
+	return net.Listen("tcp", fmt.Sprintf(":%d", t.BasePort+int(address)))
+}
+
+// Function name: Dial
+// Opens a gRPC connection to the TCP port of the given vehicle address.
+func (t TCP) Dial(address int32, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, opts...)
+	return grpc.Dial(fmt.Sprintf("localhost:%d", t.BasePort+int(address)), opts...)
+}
+
+// Function name: ServerOptions
+// The TCP transport needs no extra server options.
+func (t TCP) ServerOptions() []grpc.ServerOption {
+	return nil
+}
+
+// bufSize is the in-memory buffer of one listener.
+const bufSize = 1024 * 1024
+
+// Memory keeps every vehicle server in process on a bufconn listener, so no OS ports are used.
+// It serves the same VehicleService handlers as the TCP transport.
+type Memory struct {
+	mu        sync.Mutex
+	listeners map[int32]*bufconn.Listener
+}
+
+// Function name: NewMemory
+// Creates an empty in-memory network.
+func NewMemory() *Memory {
+	return &Memory{listeners: make(map[int32]*bufconn.Listener)}
+}
+
+// Function name: Listen
+// Registers a new in-memory listener for the vehicle address, replacing any previous one.
+func (m *Memory) Listen(address int32) (net.Listener, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	lis := bufconn.Listen(bufSize)
+	m.listeners[address] = lis
+	return lis, nil
+}
+
+// Function name: Dial
+// Opens a gRPC connection to the in-memory listener of the given vehicle address.
+func (m *Memory) Dial(address int32, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	opts = append([]grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			m.mu.Lock()
+			lis, ok := m.listeners[address]
+			m.mu.Unlock()
+
+			if !ok {
+				return nil, fmt.Errorf("no vehicle listening on address %d", address)
+			}
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}, opts...)
+	return grpc.Dial(fmt.Sprintf("passthrough:///vehicle-%d", address), opts...)
+}
+
+// Function name: ServerOptions
+// The in-memory transport needs no extra server options.
+func (m *Memory) ServerOptions() []grpc.ServerOption {
+	return nil
+}
@@ -0,0 +1,60 @@
+package utills
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sync"
+)
+
+// Function name: NewStream
+// Returns a generator for one named subsystem, derived from the run seed.
+// Each subsystem draws from its own stream so extra draws in one place do not shift the others.
+func NewStream(seed int64, name string) *rand.Rand {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return rand.New(rand.NewSource(seed ^ int64(h.Sum64())))
+}
+
+// LockedRand wraps a stream that is shared by concurrent goroutines.
+type LockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// Function name: NewLockedStream
+// Same as NewStream, but safe to use from several goroutines.
+func NewLockedStream(seed int64, name string) *LockedRand {
+	return &LockedRand{r: NewStream(seed, name)}
+}
+
+// Function name: Intn
+// Returns a non-negative pseudo-random int in [0, n).
+func (l *LockedRand) Intn(n int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Intn(n)
+}
+
+// Function name: Float64
+// Returns a pseudo-random float64 in [0.0, 1.0).
+func (l *LockedRand) Float64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Float64()
+}
+
+// Function name: NormFloat64
+// Returns a normally distributed float64 with mean 0 and standard deviation 1.
+func (l *LockedRand) NormFloat64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.NormFloat64()
+}
+
+// Function name: ExpFloat64
+// Returns an exponentially distributed float64 with rate 1.
+func (l *LockedRand) ExpFloat64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.ExpFloat64()
+}
@@ -0,0 +1,82 @@
+package verify
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// NodeView is the final state of one vehicle server at the end of an election.
+type NodeView struct {
+	Number       int32     `json:"number"`
+	Status       string    `json:"status"` // Candidate / Follower / Leader
+	Leader       int32     `json:"leader"` // leader the vehicle followed, 0 if none
+	Term         int32     `json:"term"`
+	ReceiveVotes int32     `json:"receive_votes"`
+	ElectionTime time.Time `json:"election_time"`
+}
+
+// Verdict is the outcome of the agreement check of one election.
+type Verdict struct {
+	Round     int        `json:"round"`
+	Leaders   []int32    `json:"leaders"` // leaders whose schedule the client committed
+	Pass      bool       `json:"pass"`
+	Problems  []string   `json:"problems"`
+	Undecided []int32    `json:"undecided"` // vehicles that followed no leader: a liveness gap, not a disagreement
+	Views     []NodeView `json:"views"`
+}
+
+// Function name: CheckAgreement
+// Cross-checks the final views of the vehicles of one election against the committed leaders.
+// The election passes if at most one leader was committed, no other vehicle claims to be leader,
+// every vehicle that names a leader names the committed one, and all of them adopted the same
+// ReceiveVotes and ElectionTime. Without a committed leader no vehicle may claim to be leader.
+// A vehicle that names no leader has not decided; it is listed as undecided instead of failing the check.
+func CheckAgreement(round int, committed []int32, views []NodeView) Verdict {
+	sort.Slice(views, func(i, j int) bool { return views[i].Number < views[j].Number })
+	v := Verdict{Round: round, Leaders: append([]int32(nil), committed...), Views: views}
+
+	if len(committed) > 1 {
+		v.Problems = append(v.Problems, fmt.Sprintf("dual leaders committed: %v", committed))
+	}
+
+	var claimed []int32
+	for _, view := range views {
+		if view.Status == "Leader" {
+			claimed = append(claimed, view.Number)
+		}
+	}
+	if len(claimed) > 1 {
+		v.Problems = append(v.Problems, fmt.Sprintf("dual leaders claimed: %v", claimed))
+	}
+
+	if len(committed) == 0 {
+		for _, n := range claimed {
+			v.Problems = append(v.Problems, fmt.Sprintf("vehicle %d claims to be leader but no leader was committed", n))
+		}
+	} else {
+		leader := committed[0]
+		var reference *NodeView
+		for i, view := range views {
+			if view.Leader == 0 {
+				v.Undecided = append(v.Undecided, view.Number)
+				continue
+			}
+			if view.Leader != leader {
+				v.Problems = append(v.Problems, fmt.Sprintf("vehicle %d followed %d instead of %d", view.Number, view.Leader, leader))
+				continue
+			}
+			if reference == nil {
+				reference = &views[i]
+				continue
+			}
+			if view.ReceiveVotes != reference.ReceiveVotes || !view.ElectionTime.Equal(reference.ElectionTime) {
+				v.Problems = append(v.Problems, fmt.Sprintf("vehicle %d adopted votes %d at %v, vehicle %d adopted votes %d at %v",
+					view.Number, view.ReceiveVotes, view.ElectionTime, reference.Number, reference.ReceiveVotes, reference.ElectionTime))
+			}
+		}
+	}
+
+	v.Pass = len(v.Problems) == 0
+	return v
+}
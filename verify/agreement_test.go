@@ -0,0 +1,44 @@
+package verify
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCheckAgreement(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	leader := NodeView{Number: 1, Status: "Leader", Leader: 1, ReceiveVotes: 3, ElectionTime: at}
+	follower := func(number int32, following int32) NodeView {
+		return NodeView{Number: number, Status: "Follower", Leader: following, ReceiveVotes: 3, ElectionTime: at}
+	}
+
+	tests := []struct {
+		name      string
+		committed []int32
+		views     []NodeView
+		pass      bool
+		problems  int
+		undecided []int32
+	}{
+		{"agreement", []int32{1}, []NodeView{leader, follower(2, 1), follower(3, 1)}, true, 0, nil},
+		{"undecided is not a disagreement", []int32{1}, []NodeView{leader, follower(2, 1), follower(3, 0)}, true, 0, []int32{3}},
+		{"other leader followed", []int32{1}, []NodeView{leader, follower(2, 1), follower(3, 2)}, false, 1, nil},
+		{"dual leaders committed", []int32{1, 2}, []NodeView{leader, follower(2, 1)}, false, 1, nil},
+		{"dual leaders claimed", []int32{1}, []NodeView{leader, {Number: 2, Status: "Leader", Leader: 1, ReceiveVotes: 3, ElectionTime: at}}, false, 1, nil},
+		{"claimed without commit", nil, []NodeView{leader, follower(2, 0)}, false, 1, nil},
+		{"different votes adopted", []int32{1}, []NodeView{leader, {Number: 2, Status: "Follower", Leader: 1, ReceiveVotes: 2, ElectionTime: at}}, false, 1, nil},
+		{"no election", nil, []NodeView{follower(1, 0), follower(2, 0)}, true, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := CheckAgreement(1, tt.committed, tt.views)
+			if v.Pass != tt.pass || len(v.Problems) != tt.problems {
+				t.Fatalf("pass %v with problems %q, want pass %v with %d problems", v.Pass, v.Problems, tt.pass, tt.problems)
+			}
+			if !reflect.DeepEqual(v.Undecided, tt.undecided) {
+				t.Fatalf("undecided %v, want %v", v.Undecided, tt.undecided)
+			}
+		})
+	}
+}
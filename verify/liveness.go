@@ -0,0 +1,48 @@
+package verify
+
+import (
+	"sort"
+
+	metrics "main/metrics"
+)
+
+// Wait is how long one vehicle waited from its first round to its release.
+type Wait struct {
+	Vehicle    int32   `json:"vehicle"`
+	HV         bool    `json:"hv"`
+	FirstRound int     `json:"first_round"`
+	PassRound  int     `json:"pass_round"` // 0 if the vehicle never passed
+	WaitMs     float64 `json:"wait_ms"`    // until the end of the run if the vehicle never passed
+	Elections  int     `json:"elections"`  // elections held while the vehicle waited, 0 if it passed without one
+}
+
+// LivenessReport is the outcome of the bounded-wait check of one run.
+type LivenessReport struct {
+	BoundMs float64 `json:"bound_ms"`
+	Worst   Wait    `json:"worst"`   // longest wait of the run
+	Starved []Wait  `json:"starved"` // vehicles that exceeded the bound or never passed, longest first
+}
+
+// Function name: CheckLiveness
+// Tracks every vehicle from its first round to its release and flags the ones that waited longer than the bound.
+// A vehicle that never passed waited until the end of the run and is always flagged.
+func CheckLiveness(vehicles []metrics.Vehicle, endMs float64, boundMs float64) LivenessReport {
+	report := LivenessReport{BoundMs: boundMs}
+	for _, v := range vehicles {
+		w := Wait{Vehicle: v.Number, HV: v.HV, FirstRound: v.FirstRound, PassRound: v.PassRound, Elections: v.Elections}
+		if v.PassRound == 0 {
+			w.WaitMs = endMs - v.ArrivalMs
+		} else {
+			w.WaitMs = v.DelayMs
+		}
+
+		if w.WaitMs > report.Worst.WaitMs || report.Worst.Vehicle == 0 {
+			report.Worst = w
+		}
+		if v.PassRound == 0 || w.WaitMs > boundMs {
+			report.Starved = append(report.Starved, w)
+		}
+	}
+	sort.SliceStable(report.Starved, func(i, j int) bool { return report.Starved[i].WaitMs > report.Starved[j].WaitMs })
+	return report
+}
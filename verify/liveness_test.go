@@ -0,0 +1,52 @@
+package verify
+
+import (
+	"reflect"
+	"testing"
+
+	metrics "main/metrics"
+)
+
+func TestCheckLiveness(t *testing.T) {
+	passed := func(number int32, arrivalMs float64, delayMs float64, elections int) metrics.Vehicle {
+		return metrics.Vehicle{Number: number, FirstRound: 1, PassRound: 1, ArrivalMs: arrivalMs, DelayMs: delayMs, Elections: elections}
+	}
+	waiting := func(number int32, arrivalMs float64, elections int) metrics.Vehicle {
+		return metrics.Vehicle{Number: number, FirstRound: 2, ArrivalMs: arrivalMs, DelayMs: -1, Elections: elections}
+	}
+
+	tests := []struct {
+		name     string
+		vehicles []metrics.Vehicle
+		worst    Wait
+		starved  []int32
+	}{
+		{"no vehicles", nil, Wait{}, nil},
+		{"all within the bound", []metrics.Vehicle{passed(1, 0, 400, 1), passed(2, 0, 900, 3)},
+			Wait{Vehicle: 2, FirstRound: 1, PassRound: 1, WaitMs: 900, Elections: 3}, nil},
+		{"wait at the bound", []metrics.Vehicle{passed(1, 0, 1000, 2)},
+			Wait{Vehicle: 1, FirstRound: 1, PassRound: 1, WaitMs: 1000, Elections: 2}, nil},
+		{"over the bound, longest first", []metrics.Vehicle{passed(1, 0, 1500, 4), passed(2, 0, 300, 0), passed(3, 0, 2500, 6)},
+			Wait{Vehicle: 3, FirstRound: 1, PassRound: 1, WaitMs: 2500, Elections: 6}, []int32{3, 1}},
+		{"never passed waits until the end", []metrics.Vehicle{passed(1, 0, 400, 1), waiting(2, 4500, 2)},
+			Wait{Vehicle: 2, FirstRound: 2, WaitMs: 500, Elections: 2}, []int32{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := CheckLiveness(tt.vehicles, 5000, 1000)
+			if report.BoundMs != 1000 {
+				t.Fatalf("bound = %v, want 1000", report.BoundMs)
+			}
+			if report.Worst != tt.worst {
+				t.Fatalf("worst = %+v, want %+v", report.Worst, tt.worst)
+			}
+			var starved []int32
+			for _, w := range report.Starved {
+				starved = append(starved, w.Vehicle)
+			}
+			if !reflect.DeepEqual(starved, tt.starved) {
+				t.Fatalf("starved = %v, want %v", starved, tt.starved)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package verify
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	clock "main/clock"
+	direction "main/config/directionBoolean"
+)
+
+// Violation is one pair of vehicles that were inside the intersection at the same time with conflicting movements.
+type Violation struct {
+	Round      int     `json:"round"`
+	AtMs       float64 `json:"at_ms"` // since the start of the run
+	Vehicle    int32   `json:"vehicle"`
+	Direction  string  `json:"direction"`
+	Conflict   int32   `json:"conflict"` // vehicle already inside the intersection
+	ConflictOn string  `json:"conflict_direction"`
+}
+
+// Function name: String
+// Describes the violation for logs.
+func (v Violation) String() string {
+	return fmt.Sprintf("round %d at %.0f ms: vehicle %d (%s) entered while vehicle %d (%s) was crossing",
+		v.Round, v.AtMs, v.Vehicle, v.Direction, v.Conflict, v.ConflictOn)
+}
+
+// Monitor is the runtime safety monitor.
+// It keeps the vehicles currently inside the intersection with their movements and checks every vehicle
+// that enters against all of them with the DirectionBoolean compatibility in both directions.
+// It is safe to use from the election goroutines.
+type Monitor struct {
+	mu         sync.Mutex
+	clock      clock.Clock
+	start      time.Time
+	strict     bool
+	round      int
+	inside     map[int32]string
+	violations []Violation
+}
+
+// Function name: NewMonitor
+// Creates a monitor with an empty intersection; in strict mode Enter fails on the first violation.
+func NewMonitor(clk clock.Clock, strict bool) *Monitor {
+	return &Monitor{
+		clock:  clk,
+		start:  clk.Now(),
+		strict: strict,
+		inside: make(map[int32]string),
+	}
+}
+
+// Function name: BeginRound
+// Sets the round that later violations are attributed to.
+func (m *Monitor) BeginRound(round int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.round = round
+}
+
+// Function name: Enter
+// Records that a vehicle entered the intersection and checks it against every vehicle still inside.
+// In strict mode the first conflict is returned as an error.
+func (m *Monitor) Enter(number int32, movement string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var others []int32
+	for n := range m.inside {
+		others = append(others, n)
+	}
+	sort.Slice(others, func(i, j int) bool { return others[i] < others[j] })
+
+	var first *Violation
+	for _, n := range others {
+		if n == number || direction.Compatible(movement, m.inside[n]) {
+			continue
+		}
+		v := Violation{
+			Round:      m.round,
+			AtMs:       float64(m.clock.Now().Sub(m.start)) / float64(time.Millisecond),
+			Vehicle:    number,
+			Direction:  movement,
+			Conflict:   n,
+			ConflictOn: m.inside[n],
+		}
+		m.violations = append(m.violations, v)
+		if first == nil {
+			first = &v
+		}
+	}
+	m.inside[number] = movement
+
+	if m.strict && first != nil {
+		return fmt.Errorf("safety violation: %v", first)
+	}
+	return nil
+}
+
+// Function name: Leave
+// Records that the vehicles finished crossing.
+func (m *Monitor) Leave(numbers ...int32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, n := range numbers {
+		delete(m.inside, n)
+	}
+}
+
+// Function name: Violations
+// Returns every violation seen so far in order of detection.
+func (m *Monitor) Violations() []Violation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Violation(nil), m.violations...)
+}
@@ -0,0 +1,50 @@
+package verify
+
+import (
+	"testing"
+	"time"
+
+	clock "main/clock"
+)
+
+func TestMonitor(t *testing.T) {
+	type step struct {
+		enter  int32 // vehicle entering, 0 for a step that only leaves
+		leave  []int32
+		strict bool // the step must fail in strict mode
+	}
+	movements := map[int32]string{1: "Rs", 2: "Ls", 3: "Rl", 4: "Ur"}
+
+	tests := []struct {
+		name       string
+		steps      []step
+		violations int
+	}{
+		{"compatible group", []step{{enter: 1}, {enter: 2}, {enter: 4}}, 0},
+		{"conflict inside", []step{{enter: 1}, {enter: 2}, {enter: 3, strict: true}}, 2},
+		{"conflict after leaving", []step{{enter: 1}, {enter: 2}, {leave: []int32{1, 2}}, {enter: 3}}, 0},
+		{"partial leave", []step{{enter: 1}, {enter: 2}, {leave: []int32{1}}, {enter: 3, strict: true}}, 1},
+	}
+	for _, tt := range tests {
+		for _, strict := range []bool{false, true} {
+			m := NewMonitor(clock.NewVirtual(time.Now()), strict)
+			m.BeginRound(1)
+			for _, s := range tt.steps {
+				m.Leave(s.leave...)
+				if s.enter == 0 {
+					continue
+				}
+				err := m.Enter(s.enter, movements[s.enter])
+				if strict && (err != nil) != s.strict {
+					t.Fatalf("%s: Enter(%d) error %v in strict mode", tt.name, s.enter, err)
+				}
+				if !strict && err != nil {
+					t.Fatalf("%s: Enter(%d) error %v in report mode", tt.name, s.enter, err)
+				}
+			}
+			if got := len(m.Violations()); got != tt.violations {
+				t.Fatalf("%s: %d violations, want %d", tt.name, got, tt.violations)
+			}
+		}
+	}
+}